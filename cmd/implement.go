@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 
+	"github.com/jumppad-labs/spektacular/internal/launch"
 	"github.com/jumppad-labs/spektacular/internal/output"
 	"github.com/jumppad-labs/spektacular/internal/steps/implement"
 	"github.com/jumppad-labs/spektacular/internal/store"
@@ -46,15 +47,17 @@ var implementCmd = &cobra.Command{
 }
 
 var implementNewCmd = &cobra.Command{
-	Use:   "new",
-	Short: "Create a new implement workflow against an existing plan",
-	RunE:  runImplementNew,
+	Use:         "new",
+	Short:       "Create a new implement workflow against an existing plan",
+	RunE:        runImplementNew,
+	Annotations: map[string]string{recordRunAnnotation: "plan", trustAnnotation: "true"},
 }
 
 var implementGotoCmd = &cobra.Command{
-	Use:   "goto",
-	Short: "Jump to a named step",
-	RunE:  runImplementGoto,
+	Use:         "goto",
+	Short:       "Jump to a named step",
+	RunE:        runImplementGoto,
+	Annotations: map[string]string{recordRunAnnotation: "plan", trustAnnotation: "true"},
 }
 
 var implementStatusCmd = &cobra.Command{
@@ -115,9 +118,8 @@ func runImplementNew(cmd *cobra.Command, _ []string) error {
 
 	// Precondition: the plan file must exist before an implement workflow
 	// can run against it. The workflow operates on an already-approved plan.
-	planPath := filepath.Join(root, implement.PlanFilePath(cfg.Plan.Config.Directory, input.Name))
-	if _, statErr := os.Stat(planPath); statErr != nil {
-		return fmt.Errorf("plan file not found at %s — run 'plan new' first or check the name", planPath)
+	if err := launch.RequirePlan(store.NewFileStore(root, "project"), cfg, input.Name); err != nil {
+		return err
 	}
 
 	statePath := stateFilePath(dataDir)
@@ -127,7 +129,7 @@ func runImplementNew(cmd *cobra.Command, _ []string) error {
 		_ = os.Remove(statePath)
 	}
 
-	wfCfg := workflow.Config{Command: cfg.Command, DryRun: dryRun, SpecDir: cfg.Spec.Config.Directory, PlanDir: cfg.Plan.Config.Directory}
+	wfCfg := launch.WorkflowConfig(cfg, dryRun)
 	steps := implement.Steps()
 	out := output.New(cmd.OutOrStdout(), globalFields)
 	wf := workflow.New(steps, statePath, wfCfg, store.NewFileStore(root, "project"), out)
@@ -137,7 +139,7 @@ func runImplementNew(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	if err := wf.Next(); err != nil {
+	if err := wf.Next(cmd.Context()); err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
 	return nil
@@ -186,7 +188,7 @@ func runImplementGoto(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	wfCfg := workflow.Config{Command: cfg.Command, DryRun: dryRun, SpecDir: cfg.Spec.Config.Directory, PlanDir: cfg.Plan.Config.Directory}
+	wfCfg := launch.WorkflowConfig(cfg, dryRun)
 	steps := implement.Steps()
 	out := output.New(cmd.OutOrStdout(), globalFields)
 	wf := workflow.New(steps, stateFilePath(dataDir), wfCfg, store.NewFileStore(root, "project"), out)
@@ -205,7 +207,7 @@ func runImplementGoto(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	if err := wf.Goto(stepVal); err != nil {
+	if err := wf.Goto(cmd.Context(), stepVal); err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
 	return nil
@@ -215,3 +215,20 @@ func TestImplementSteps_SchemaOutput(t *testing.T) {
 	require.NoError(t, rootCmd.Execute())
 	require.Contains(t, stdout.String(), `"steps"`)
 }
+
+func TestImplementNew_SuggestsCloseMatch(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	dataDir := filepath.Join(dir, ".spektacular")
+	require.NoError(t, os.MkdirAll(dataDir, 0o755))
+	writeFixturePlan(t, dataDir, "my-feature")
+
+	setupImplementCmd(t)
+	require.NoError(t, implementCmd.PersistentFlags().Set("schema", "false"))
+	rootCmd.SetArgs([]string{"implement", "new", "--data", `{"name":"my-featrue"}`})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "plan file not found")
+	require.Contains(t, err.Error(), "did you mean my-feature?")
+}
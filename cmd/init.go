@@ -46,6 +46,13 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	fmt.Fprintf(cmd.OutOrStdout(), "Spektacular initialised for %s.\n", a.Name())
 	fmt.Fprintf(cmd.OutOrStdout(), "  Project:  %s\n", filepath.Join(cwd, ".spektacular"))
+	for _, f := range agent.DetectInstructionFiles(cwd, a) {
+		if f.Honored {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Rules:    %s (read natively by %s)\n", f.Name, a.Name())
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  Rules:    %s (not read by %s — reference it from your agent's own rules to apply it)\n", f.Name, a.Name())
+	}
 
 	return a.Install(cwd, cfg, cmd.OutOrStdout())
 }
@@ -182,3 +182,17 @@ func TestInit_Idempotent(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "keep-skill", string(skillData))
 }
+
+func TestInit_ReportsProjectInstructionFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("# house rules\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# house rules\n"), 0644))
+
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"init", "claude"})
+	require.NoError(t, rootCmd.Execute())
+
+	require.Contains(t, stdout.String(), "Rules:    CLAUDE.md (read natively by claude)")
+	require.Contains(t, stdout.String(), "Rules:    AGENTS.md (not read by claude")
+}
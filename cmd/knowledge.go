@@ -90,17 +90,17 @@ var knowledgeScopePathInputSchema = &schemaObj{
 }
 
 // newKnowledgeSet builds a knowledge.Set from the project configuration,
-// resolving relative source locations against the working directory.
+// resolving relative source locations against the project root.
 func newKnowledgeSet() (*knowledge.Set, error) {
 	cfg, err := loadConfig()
 	if err != nil {
 		return nil, err
 	}
-	cwd, err := os.Getwd()
+	root, err := projectRoot()
 	if err != nil {
-		return nil, fmt.Errorf("getting working directory: %w", err)
+		return nil, err
 	}
-	return knowledge.NewSet(cfg, cwd)
+	return knowledge.NewSet(cfg, root)
 }
 
 func runKnowledgeSearch(cmd *cobra.Command, args []string) error {
@@ -111,7 +111,7 @@ func runKnowledgeSearch(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
-	hits, err := set.Search(args[0])
+	hits, err := set.Search(cmd.Context(), args[0])
 	if err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
@@ -154,7 +154,7 @@ func runKnowledgeList(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
-	entries, err := set.List()
+	entries, err := set.List(cmd.Context())
 	if err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
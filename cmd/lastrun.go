@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jumppad-labs/spektacular/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// recordRunAnnotation marks commands whose invocations are recorded in
+// .spektacular/last-run.json for external tooling. Its value names the
+// workflow ("spec" or "plan") whose configured directory holds the output.
+const recordRunAnnotation = "spektacular/record-run"
+
+// Run statuses recorded in last-run.json.
+const (
+	RunStatusSuccess   = "success"
+	RunStatusFailed    = "failed"
+	RunStatusCancelled = "cancelled"
+)
+
+// LastRun is the machine-readable record of the most recent workflow
+// command, written to .spektacular/last-run.json.
+type LastRun struct {
+	Command    string    `json:"command"`
+	Args       []string  `json:"args"`
+	Status     string    `json:"status"`
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Step       string    `json:"step,omitempty"`
+	ResultDir  string    `json:"result_dir,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the outcome of the last workflow command",
+	Args:  cobra.NoArgs,
+	RunE:  runStatus,
+}
+
+func lastRunPath(dataDir string) string {
+	return filepath.Join(dataDir, "last-run.json")
+}
+
+// executeContext runs the root command and, for commands annotated with
+// recordRunAnnotation, records the outcome whether it succeeded or not.
+func executeContext(ctx context.Context) error {
+	started := time.Now().UTC()
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if cmd != nil && cmd.Annotations[recordRunAnnotation] != "" {
+		if recErr := recordLastRun(ctx, cmd, started, err); recErr != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: recording last run: %v\n", recErr)
+		}
+	}
+	return err
+}
+
+// recordLastRun writes last-run.json for cmd. Nothing is recorded for
+// --schema invocations or when the project has no .spektacular directory.
+func recordLastRun(ctx context.Context, cmd *cobra.Command, started time.Time, runErr error) error {
+	if schema, _ := cmd.Flags().GetBool("schema"); schema {
+		return nil
+	}
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil
+	}
+
+	run := LastRun{
+		Command:    cmd.CommandPath(),
+		Args:       invocationArgs(cmd),
+		Status:     RunStatusSuccess,
+		StartedAt:  started,
+		FinishedAt: time.Now().UTC(),
+	}
+	switch {
+	case errors.Is(runErr, context.Canceled) || ctx.Err() != nil:
+		run.Status = RunStatusCancelled
+		run.ExitCode = 130
+	case runErr != nil:
+		run.Status = RunStatusFailed
+		run.ExitCode = 1
+	}
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+	run.Name, run.Step = workflowPosition(stateFilePath(dir))
+	run.ResultDir = resultDir(cmd.Annotations[recordRunAnnotation], filepath.Dir(dir))
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling last run: %w", err)
+	}
+	return writeFileAtomic(lastRunPath(dir), append(data, '\n'))
+}
+
+// invocationArgs reconstructs the flags and positional arguments cmd was
+// invoked with.
+func invocationArgs(cmd *cobra.Command) []string {
+	args := []string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return append(args, cmd.Flags().Args()...)
+}
+
+// resultDir returns the absolute output directory for the given workflow
+// kind, or "" when it cannot be determined.
+func resultDir(kind, root string) string {
+	cfg, err := loadConfig()
+	if err != nil {
+		return ""
+	}
+	switch kind {
+	case "spec":
+		return filepath.Join(root, cfg.Spec.Config.Directory)
+	case "plan":
+		return filepath.Join(root, cfg.Plan.Config.Directory)
+	}
+	return ""
+}
+
+// workflowPosition reads the active workflow's name and current step from
+// the state file, returning empty strings when there is none.
+func workflowPosition(statePath string) (string, string) {
+	raw, err := os.ReadFile(statePath)
+	if err != nil {
+		return "", ""
+	}
+	var state struct {
+		CurrentStep string         `json:"current_step"`
+		Data        map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return "", ""
+	}
+	name, _ := state.Data["name"].(string)
+	return name, state.CurrentStep
+}
+
+// writeFileAtomic replaces path with data via a temp file and rename, so
+// readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename has succeeded
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+func runStatus(cmd *cobra.Command, _ []string) error {
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(lastRunPath(dir))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no workflow command has been recorded yet in %s", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("reading last run: %w", err)
+	}
+	var run LastRun
+	if err := json.Unmarshal(raw, &run); err != nil {
+		return fmt.Errorf("parsing %s: %w", lastRunPath(dir), err)
+	}
+	return output.New(cmd.OutOrStdout(), globalFields).WriteResult(run)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readLastRun(t *testing.T, dir string) LastRun {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(dir, ".spektacular", "last-run.json"))
+	require.NoError(t, err)
+	var run LastRun
+	require.NoError(t, json.Unmarshal(raw, &run))
+	return run
+}
+
+func executeSpecNewForLastRun(t *testing.T, ctx context.Context, args ...string) error {
+	t.Helper()
+	resetSpecCommandFlags(t)
+	setupImplementCmd(t)
+	rootCmd.SetArgs(append([]string{"spec", "new"}, args...))
+	return executeContext(ctx)
+}
+
+func TestLastRun_RecordsSuccess(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "")
+
+	require.NoError(t, executeSpecNewForLastRun(t, context.Background(), "--data", `{"name":"billing","id":"EXT-1"}`))
+
+	run := readLastRun(t, dir)
+	require.Equal(t, "spektacular spec new", run.Command)
+	require.Contains(t, run.Args, `--data={"name":"billing","id":"EXT-1"}`)
+	require.Equal(t, RunStatusSuccess, run.Status)
+	require.Zero(t, run.ExitCode)
+	require.Empty(t, run.Error)
+	require.Equal(t, "ext-1-billing", run.Name)
+	require.Equal(t, "overview", run.Step)
+	require.Equal(t, filepath.Join(dir, ".spektacular", "specs"), run.ResultDir)
+	require.False(t, run.FinishedAt.Before(run.StartedAt))
+}
+
+func TestLastRun_RecordsSpecReview(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandFile(t, dir, "ext-1-billing")
+
+	resetSpecCommandFlags(t)
+	setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"spec", "review", "ext-1-billing"})
+	require.NoError(t, executeContext(context.Background()))
+
+	run := readLastRun(t, dir)
+	require.Equal(t, "spektacular spec review", run.Command)
+	require.Contains(t, run.Args, "ext-1-billing")
+	require.Equal(t, RunStatusSuccess, run.Status)
+	require.Equal(t, filepath.Join(dir, ".spektacular", "specs"), run.ResultDir)
+}
+
+func TestLastRun_RecordsFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandFile(t, dir, "ext-1-billing")
+
+	err := executeSpecNewForLastRun(t, context.Background(), "--data", `{"name":"billing","id":"EXT-1"}`)
+	require.Error(t, err)
+
+	run := readLastRun(t, dir)
+	require.Equal(t, RunStatusFailed, run.Status)
+	require.Equal(t, 1, run.ExitCode)
+	require.Equal(t, err.Error(), run.Error)
+}
+
+func TestLastRun_RecordsCancellation(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = executeSpecNewForLastRun(t, ctx, "--data", `{"name":"billing"}`)
+
+	run := readLastRun(t, dir)
+	require.Equal(t, RunStatusCancelled, run.Status)
+	require.Equal(t, 130, run.ExitCode)
+}
+
+func TestLastRun_SkipsSchemaAndUninitialisedProjects(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "")
+
+	require.NoError(t, executeSpecNewForLastRun(t, context.Background(), "--schema"))
+	require.NoFileExists(t, filepath.Join(dir, ".spektacular", "last-run.json"))
+
+	bare := t.TempDir()
+	t.Chdir(bare)
+	require.Error(t, executeSpecNewForLastRun(t, context.Background()))
+	require.NoDirExists(t, filepath.Join(bare, ".spektacular"))
+}
+
+func TestStatus_PrintsLastRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "")
+	require.NoError(t, executeSpecNewForLastRun(t, context.Background(), "--data", `{"name":"billing","id":"EXT-1"}`))
+
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"status"})
+	require.NoError(t, rootCmd.Execute())
+
+	var run LastRun
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &run))
+	require.Equal(t, RunStatusSuccess, run.Status)
+	require.Equal(t, "ext-1-billing", run.Name)
+}
+
+func TestStatus_NoRecordedRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "")
+
+	setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"status"})
+	require.ErrorContains(t, rootCmd.Execute(), "no workflow command has been recorded")
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/jumppad-labs/spektacular/internal/launch"
 	"github.com/jumppad-labs/spektacular/internal/output"
 	"github.com/jumppad-labs/spektacular/internal/steps/plan"
 	"github.com/jumppad-labs/spektacular/internal/store"
@@ -42,15 +43,24 @@ var planCmd = &cobra.Command{
 }
 
 var planNewCmd = &cobra.Command{
-	Use:   "new",
+	Use:   "new [-]",
 	Short: "Create a new plan workflow",
-	RunE:  runPlanNew,
+	Long: `Create a new plan workflow for the spec named in --data.
+
+Pass "-" to plan a spec piped on stdin instead, e.g.
+  cat spec.md | spektacular plan new -
+The plan name comes from --data or, failing that, the spec's H1 title. The
+spec is kept beside the plan unless --save copies it into the spec directory.`,
+	Args:        cobra.MaximumNArgs(1),
+	RunE:        runPlanNew,
+	Annotations: map[string]string{recordRunAnnotation: "plan", trustAnnotation: "true"},
 }
 
 var planGotoCmd = &cobra.Command{
-	Use:   "goto",
-	Short: "Jump to a named step",
-	RunE:  runPlanGoto,
+	Use:         "goto",
+	Short:       "Jump to a named step",
+	RunE:        runPlanGoto,
+	Annotations: map[string]string{recordRunAnnotation: "plan", trustAnnotation: "true"},
 }
 
 var planStatusCmd = &cobra.Command{
@@ -65,7 +75,7 @@ var planStepsCmd = &cobra.Command{
 	RunE:  runPlanSteps,
 }
 
-func runPlanNew(cmd *cobra.Command, _ []string) error {
+func runPlanNew(cmd *cobra.Command, args []string) error {
 	if schema, _ := cmd.Flags().GetBool("schema"); schema {
 		s := commandSchema{
 			Input: &schemaObj{
@@ -82,15 +92,38 @@ func runPlanNew(cmd *cobra.Command, _ []string) error {
 
 	dataStr, _ := cmd.Flags().GetString("data")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	save, _ := cmd.Flags().GetBool("save")
 
-	if dataStr == "" {
-		return fmt.Errorf("--data is required (e.g. --data '{\"name\":\"my-feature\"}')")
+	var pipedSpec string
+	if len(args) == 1 {
+		if args[0] != stdinSpecArg {
+			return fmt.Errorf("unexpected argument %q; pass %q to read the spec from stdin", args[0], stdinSpecArg)
+		}
+		content, err := readPipedSpec(cmd)
+		if err != nil {
+			return err
+		}
+		pipedSpec = content
+	} else if save {
+		return fmt.Errorf("--save only applies to a spec piped with %q", stdinSpecArg)
 	}
+
 	var input struct {
 		Name string `json:"name"`
 	}
-	if err := json.Unmarshal([]byte(dataStr), &input); err != nil {
-		return fmt.Errorf("parsing --data: %w", err)
+	switch {
+	case dataStr != "":
+		if err := json.Unmarshal([]byte(dataStr), &input); err != nil {
+			return fmt.Errorf("parsing --data: %w", err)
+		}
+	case pipedSpec != "":
+		name, err := planNameFromSpec(pipedSpec)
+		if err != nil {
+			return err
+		}
+		input.Name = name
+	default:
+		return fmt.Errorf("--data is required (e.g. --data '{\"name\":\"my-feature\"}')")
 	}
 	if input.Name == "" || !nameRegexp.MatchString(input.Name) || len(input.Name) > 64 {
 		return fmt.Errorf("name must match ^[a-z0-9_-]+$ and be at most 64 characters")
@@ -109,6 +142,15 @@ func runPlanNew(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// Precondition: a plan is written from an existing spec unless one was
+	// piped in on stdin.
+	st := store.NewFileStore(root, "project")
+	if pipedSpec == "" {
+		if err := launch.RequireSpec(st, cfg, input.Name); err != nil {
+			return err
+		}
+	}
+
 	statePath := stateFilePath(dataDir)
 	if dryRun {
 		statePath += ".dryrun-tmp"
@@ -116,17 +158,29 @@ func runPlanNew(cmd *cobra.Command, _ []string) error {
 		_ = os.Remove(statePath)
 	}
 
-	wfCfg := workflow.Config{Command: cfg.Command, DryRun: dryRun, SpecDir: cfg.Spec.Config.Directory, PlanDir: cfg.Plan.Config.Directory}
+	wfCfg := launch.WorkflowConfig(cfg, dryRun)
 	steps := plan.Steps()
 	out := output.New(cmd.OutOrStdout(), globalFields)
-	wf := workflow.New(steps, statePath, wfCfg, store.NewFileStore(root, "project"), out)
+	wf := workflow.New(steps, statePath, wfCfg, st, out)
 	wf.SetData("name", input.Name)
 
+	if pipedSpec != "" {
+		specFile := pipedSpecPath(wfCfg.SpecDir, wfCfg.PlanDir, input.Name, save)
+		if !dryRun {
+			if err := storePipedSpec(st, specFile, pipedSpec, save); err != nil {
+				return err
+			}
+		}
+		if !save {
+			wf.SetData(plan.SpecFileKey, specFile)
+		}
+	}
+
 	if err := readInputIntoWorkflow(cmd, wf); err != nil {
 		return err
 	}
 
-	if err := wf.Next(); err != nil {
+	if err := wf.Next(cmd.Context()); err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
 	return nil
@@ -175,7 +229,7 @@ func runPlanGoto(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	wfCfg := workflow.Config{Command: cfg.Command, DryRun: dryRun, SpecDir: cfg.Spec.Config.Directory, PlanDir: cfg.Plan.Config.Directory}
+	wfCfg := launch.WorkflowConfig(cfg, dryRun)
 	steps := plan.Steps()
 	out := output.New(cmd.OutOrStdout(), globalFields)
 	wf := workflow.New(steps, stateFilePath(dataDir), wfCfg, store.NewFileStore(root, "project"), out)
@@ -194,7 +248,7 @@ func runPlanGoto(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	if err := wf.Goto(stepVal); err != nil {
+	if err := wf.Goto(cmd.Context(), stepVal); err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
 	return nil
@@ -274,6 +328,7 @@ func init() {
 	planNewCmd.Flags().StringP("data", "d", "", `JSON input (e.g. '{"name":"my-feature"}')`)
 	planNewCmd.Flags().String("stdin", "", "Read stdin and store it in workflow data under this key")
 	planNewCmd.Flags().String("file", "", "Read a file at <path> (relative to cwd) and store its contents under the filename's basename (without extension)")
+	planNewCmd.Flags().Bool("save", false, `With "-", save the piped spec into the spec directory instead of beside the plan`)
 	planGotoCmd.Flags().StringP("data", "d", "", `JSON input (e.g. '{"step":"discovery"}')`)
 	planGotoCmd.Flags().String("stdin", "", "Read stdin and store it in workflow data under this key")
 	planGotoCmd.Flags().String("file", "", "Read a file at <path> (relative to cwd) and store its contents under the filename's basename (without extension)")
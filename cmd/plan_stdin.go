@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jumppad-labs/spektacular/internal/steps/plan"
+	"github.com/jumppad-labs/spektacular/internal/steps/spec"
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// stdinSpecArg is the `plan new` argument that reads the spec from stdin.
+const stdinSpecArg = "-"
+
+// readPipedSpec reads the spec piped to `plan new -`. It is read in full
+// before the workflow starts so nothing else competes for stdin.
+func readPipedSpec(cmd *cobra.Command) (string, error) {
+	if stdinKey, _ := cmd.Flags().GetString("stdin"); stdinKey != "" {
+		return "", fmt.Errorf("--stdin cannot be combined with %q: both read standard input", stdinSpecArg)
+	}
+	content, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("reading spec from stdin: %w", err)
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return "", fmt.Errorf("no spec was piped on stdin")
+	}
+	return string(content), nil
+}
+
+// planNameFromSpec derives a plan name from the spec's H1, dropping a
+// leading "Feature:" label and slugifying the rest: "# Feature: User Auth"
+// becomes "user-auth".
+func planNameFromSpec(content string) (string, error) {
+	title := ""
+	inFence := false
+	for line := range strings.SplitSeq(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && strings.HasPrefix(trimmed, "# ") {
+			title = strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+			break
+		}
+	}
+	if label, rest, ok := strings.Cut(title, ":"); ok && strings.EqualFold(strings.TrimSpace(label), "feature") {
+		title = strings.TrimSpace(rest)
+	}
+
+	var b strings.Builder
+	lastHyphen := true // suppresses a leading hyphen
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			b.WriteByte('-')
+			lastHyphen = true
+		}
+	}
+	name := strings.Trim(b.String(), "-")
+	if len(name) > 64 {
+		name = strings.TrimRight(name[:64], "-")
+	}
+	if name == "" {
+		return "", fmt.Errorf("cannot derive a plan name from the piped spec: it has no usable H1 title; pass --data '{\"name\":\"my-feature\"}'")
+	}
+	return name, nil
+}
+
+// pipedSpecPath is the store-relative path the piped spec is kept at: a
+// regular spec under the spec directory with save, otherwise beside the plan
+// so the spec directory is left untouched.
+func pipedSpecPath(specDir, planDir, name string, save bool) string {
+	if save {
+		return spec.SpecFilePath(specDir, name)
+	}
+	return plan.PipedSpecFilePath(planDir, name)
+}
+
+// storePipedSpec writes the piped spec to path. A saved spec never replaces
+// a different spec of the same name.
+func storePipedSpec(st store.Store, path, content string, save bool) error {
+	if save {
+		if existing, err := st.Read(path); err == nil && !bytes.Equal(existing, []byte(content)) {
+			return fmt.Errorf("spec %s already exists with different content; choose another name or drop --save", path)
+		}
+	}
+	if err := st.Write(path, []byte(content)); err != nil {
+		return fmt.Errorf("writing piped spec: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const pipedSpec = "# Feature: User Auth!\n\n## Overview\n\nLet users sign in.\n"
+
+// runPlanNewPiped runs `plan new` with spec piped on stdin.
+func runPlanNewPiped(t *testing.T, stdin string, args ...string) (map[string]any, error) {
+	t.Helper()
+	reset := func() {
+		require.NoError(t, planCmd.PersistentFlags().Set("dry-run", "false"))
+		require.NoError(t, planNewCmd.Flags().Set("data", ""))
+		require.NoError(t, planNewCmd.Flags().Set("stdin", ""))
+		require.NoError(t, planNewCmd.Flags().Set("save", "false"))
+	}
+	reset()
+	t.Cleanup(reset)
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetIn(strings.NewReader(stdin))
+	t.Cleanup(func() { rootCmd.SetIn(nil) })
+	rootCmd.SetArgs(append([]string{"plan", "new"}, args...))
+
+	if err := rootCmd.Execute(); err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	return result, nil
+}
+
+func TestPlanNameFromSpec(t *testing.T) {
+	for content, want := range map[string]string{
+		"# Feature: User Auth\n":                        "user-auth",
+		"intro\n\n# Billing -- Export (v2)\n":           "billing-export-v2",
+		"```\n# not a title\n```\n# Real_Title\n":       "real_title",
+		"# " + strings.Repeat("abc ", 30) + "\n":        strings.TrimRight(strings.Repeat("abc-", 16), "-"),
+		"# Feature: Ünïcode Ok\n":                       "n-code-ok",
+		"# Feature:   Spaced   Out   \n\n# Second H1\n": "spaced-out",
+	} {
+		got, err := planNameFromSpec(content)
+		require.NoError(t, err, content)
+		require.Equal(t, want, got, content)
+	}
+
+	_, err := planNameFromSpec("## Only a section\n")
+	require.ErrorContains(t, err, "no usable H1 title")
+	_, err = planNameFromSpec("# !!!\n")
+	require.ErrorContains(t, err, "no usable H1 title")
+}
+
+func TestPlanNew_PipedSpecKeptBesidePlan(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	result, err := runPlanNewPiped(t, pipedSpec, "-")
+	require.NoError(t, err)
+	require.Equal(t, "overview", result["step"])
+	require.Equal(t, "user-auth", result["plan_name"])
+
+	specPath := filepath.Join(dir, ".spektacular", "plans", "user-auth", "spec.md")
+	stored, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	require.Equal(t, pipedSpec, string(stored))
+	require.Contains(t, result["instruction"], specPath)
+	require.NoDirExists(t, filepath.Join(dir, ".spektacular", "specs"))
+}
+
+func TestPlanNew_PipedSpecSaved(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	result, err := runPlanNewPiped(t, pipedSpec, "-", "--save", "--data", `{"name":"auth"}`)
+	require.NoError(t, err)
+	require.Equal(t, "auth", result["plan_name"])
+
+	specPath := filepath.Join(dir, ".spektacular", "specs", "auth.md")
+	require.FileExists(t, specPath)
+	require.Contains(t, result["instruction"], specPath)
+
+	_, err = runPlanNewPiped(t, "# Feature: Something else\n", "-", "--save", "--data", `{"name":"auth"}`)
+	require.ErrorContains(t, err, "already exists with different content")
+}
+
+func TestPlanNew_PipedSpecErrors(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	_, err := runPlanNewPiped(t, "  \n", "-")
+	require.ErrorContains(t, err, "no spec was piped")
+
+	_, err = runPlanNewPiped(t, pipedSpec, "spec.md")
+	require.ErrorContains(t, err, `pass "-" to read the spec from stdin`)
+
+	_, err = runPlanNewPiped(t, pipedSpec, "-", "--stdin", "notes")
+	require.ErrorContains(t, err, "both read standard input")
+
+	_, err = runPlanNewPiped(t, "", "--save", "--data", `{"name":"auth"}`)
+	require.ErrorContains(t, err, "--save only applies")
+}
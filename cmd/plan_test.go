@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanNew_MissingSpecSuggestsCloseMatch(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandFile(t, dir, "my-feature")
+
+	_, err := runPlanNewPiped(t, "", "--data", `{"name":"my-featrue"}`)
+	require.ErrorContains(t, err, "spec file not found")
+	require.ErrorContains(t, err, "did you mean my-feature?")
+
+	_, err = runPlanNewPiped(t, "", "--data", `{"name":"billing"}`)
+	require.ErrorContains(t, err, "run 'spec new' first")
+}
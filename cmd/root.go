@@ -1,16 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
 
 	"github.com/jumppad-labs/spektacular/internal/config"
 	"github.com/spf13/cobra"
 )
 
-var version = "0.1.0"
-
 // globalFields holds the raw --fields JSON array string, available to all subcommands.
 var globalFields string
 
@@ -18,35 +20,49 @@ var rootCmd = &cobra.Command{
 	Use:     "spektacular",
 	Short:   "Agent-driven tool for spec-driven development",
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return checkTrust(cmd)
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := executeContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
 
 func configFilePath() (string, error) {
-	cwd, err := os.Getwd()
+	root, err := projectRoot()
 	if err != nil {
-		return "", fmt.Errorf("getting working directory: %w", err)
+		return "", err
 	}
-	return filepath.Join(cwd, ".spektacular", "config.yaml"), nil
+	return filepath.Join(root, ".spektacular", "config.yaml"), nil
 }
 
 // loadConfig loads the project config from the current working directory.
 // Returns defaults if the config file does not exist.
-// Returns an error if the config file exists but is invalid.
+// Returns an error if the config file exists but is invalid: a broken config
+// is never silently replaced by defaults, since the user would otherwise not
+// notice their settings were ignored.
 func loadConfig() (config.Config, error) {
 	cfgPath, err := configFilePath()
 	if err != nil {
 		return config.Config{}, err
 	}
-	if _, err := os.Stat(cfgPath); os.IsNotExist(err) {
+	if _, err := os.Stat(cfgPath); errors.Is(err, fs.ErrNotExist) {
 		return config.NewDefault(), nil
 	}
-	return config.FromYAMLFile(cfgPath)
+	cfg, err := config.FromYAMLFile(cfgPath)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("config file is present but was not applied — fix it, or remove it to use the defaults:\n%w", err)
+	}
+	return cfg, nil
 }
 
 // dataDir returns the .spektacular directory for the current working directory.
@@ -59,24 +75,47 @@ func dataDir() (string, error) {
 	return filepath.Join(root, ".spektacular"), nil
 }
 
-// projectRoot returns the project root — the current working directory. Spec,
-// plan, and knowledge directories from the config are all resolved relative to
-// this, so the configured paths (e.g. ".spektacular/specs") are project-root
-// relative rather than relative to the .spektacular data directory.
+// projectRoot returns the project root — the nearest directory at or above the
+// current working directory that holds a .spektacular directory, like git
+// finds .git. In a monorepo each package can keep its own .spektacular and
+// commands run anywhere inside it use that one. When no ancestor has one, the
+// working directory itself is the root. Spec, plan, and knowledge directories
+// from the config are all resolved relative to this, so the configured paths
+// (e.g. ".spektacular/specs") are project-root relative rather than relative
+// to the .spektacular data directory.
 func projectRoot() (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return "", fmt.Errorf("getting working directory: %w", err)
 	}
-	return cwd, nil
+	return findProjectRoot(cwd), nil
+}
+
+// findProjectRoot walks up from start to the nearest directory containing a
+// .spektacular directory, returning start when there is none.
+func findProjectRoot(start string) string {
+	for dir := start; ; {
+		if info, err := os.Stat(filepath.Join(dir, ".spektacular")); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return start
+		}
+		dir = parent
+	}
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&globalFields, "fields", "", `JSON array of output fields to include (e.g. '["step","instruction"]')`)
+	rootCmd.PersistentFlags().BoolVar(&globalTrust, "trust", false, "Skip the check that this project's config has been trusted (for CI)")
 	rootCmd.AddCommand(specCmd)
 	rootCmd.AddCommand(planCmd)
 	rootCmd.AddCommand(implementCmd)
 	rootCmd.AddCommand(knowledgeCmd)
 	rootCmd.AddCommand(skillCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(trustCmd)
 }
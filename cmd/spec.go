@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jumppad-labs/spektacular/internal/launch"
 	"github.com/jumppad-labs/spektacular/internal/output"
 	"github.com/jumppad-labs/spektacular/internal/steps/spec"
 	"github.com/jumppad-labs/spektacular/internal/store"
@@ -66,21 +68,46 @@ var statusOutputSchema = &schemaObj{
 	},
 }
 
+var reviewOutputSchema = &schemaObj{
+	Type: "object",
+	Properties: map[string]*schemaProp{
+		"step":        {Type: "string"},
+		"spec_path":   {Type: "string"},
+		"spec_name":   {Type: "string"},
+		"review_path": {Type: "string"},
+		"instruction": {Type: "string"},
+	},
+}
+
 var specCmd = &cobra.Command{
 	Use:   "spec",
 	Short: "Manage spec workflow",
 }
 
 var specNewCmd = &cobra.Command{
-	Use:   "new",
-	Short: "Create a new spec workflow",
-	RunE:  runSpecNew,
+	Use:         "new",
+	Short:       "Create a new spec workflow",
+	RunE:        runSpecNew,
+	Annotations: map[string]string{recordRunAnnotation: "spec", trustAnnotation: "true"},
 }
 
 var specGotoCmd = &cobra.Command{
-	Use:   "goto",
-	Short: "Jump to a named step",
-	RunE:  runSpecGoto,
+	Use:         "goto",
+	Short:       "Jump to a named step",
+	RunE:        runSpecGoto,
+	Annotations: map[string]string{recordRunAnnotation: "spec", trustAnnotation: "true"},
+}
+
+var specReviewCmd = &cobra.Command{
+	Use:   "review <name>",
+	Short: "Have the agent critique an existing spec",
+	Long: `Have the agent critique an existing spec for ambiguities, untestable
+acceptance criteria and missing non-goals. The findings are written beside the
+spec as <name>.review.md and each suggested edit is offered to the user to
+apply or skip. The active spec workflow's state is left untouched.`,
+	Args:        cobra.MaximumNArgs(1),
+	RunE:        runSpecReview,
+	Annotations: map[string]string{recordRunAnnotation: "spec", trustAnnotation: "true"},
 }
 
 var specStatusCmd = &cobra.Command{
@@ -193,17 +220,25 @@ func runSpecNew(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	resolved, err := spec.ResolveIdentifier(spec.IdentifierRequest{
-		Name:    input.Name,
-		ID:      input.ID,
-		Method:  cfg.Spec.IDMethod,
-		SpecDir: cfg.Spec.Config.Directory,
-		Store:   st,
-		Now:     specIdentifierNow,
+	onExists, _ := cmd.Flags().GetString("on-exists")
+	quick, _ := cmd.Flags().GetBool("quick")
+	started, err := launch.Spec(st, cfg, launch.SpecRequest{
+		Name:     input.Name,
+		ID:       input.ID,
+		OnExists: onExists,
+		Quick:    quick,
+		DryRun:   dryRun,
+		Now:      specIdentifierNow,
 	})
+	if errors.Is(err, spec.ErrSpecExists) {
+		return fmt.Errorf("%w — pass --on-exists=version to create a numbered sibling, or --on-exists=overwrite to archive it and start over", err)
+	}
 	if err != nil {
 		return err
 	}
+	if started.Archived != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "archived existing spec %s to %s\n", started.Name, started.Archived)
+	}
 
 	statePath := stateFilePath(dataDir)
 	if dryRun {
@@ -212,18 +247,24 @@ func runSpecNew(cmd *cobra.Command, _ []string) error {
 		_ = os.Remove(statePath)
 	}
 
-	wfCfg := workflow.Config{Command: cfg.Command, DryRun: dryRun, SpecDir: cfg.Spec.Config.Directory, PlanDir: cfg.Plan.Config.Directory}
-	steps := spec.Steps()
 	out := output.New(cmd.OutOrStdout(), globalFields)
-	wf := workflow.New(steps, statePath, wfCfg, st, out)
+	wf := workflow.New(started.Steps, statePath, launch.WorkflowConfig(cfg, dryRun), st, out)
 	for k, v := range extraData {
-		if k != "name" {
+		if k != "name" && k != "mode" {
 			wf.SetData(k, v)
 		}
 	}
-	wf.SetData("name", resolved.Name)
+	started.Apply(wf)
+
+	warnings, err := spec.ScaffoldWarnings()
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+	}
 
-	if err := wf.Next(); err != nil {
+	if err := wf.Next(cmd.Context()); err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
 	return nil
@@ -272,10 +313,9 @@ func runSpecGoto(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	wfCfg := workflow.Config{Command: cfg.Command, DryRun: dryRun, SpecDir: cfg.Spec.Config.Directory, PlanDir: cfg.Plan.Config.Directory}
-	steps := spec.Steps()
+	steps := launch.SpecSteps(stateFilePath(dataDir))
 	out := output.New(cmd.OutOrStdout(), globalFields)
-	wf := workflow.New(steps, stateFilePath(dataDir), wfCfg, store.NewFileStore(root, "project"), out)
+	wf := workflow.New(steps, stateFilePath(dataDir), launch.WorkflowConfig(cfg, dryRun), store.NewFileStore(root, "project"), out)
 
 	for k, v := range input {
 		if k != "step" {
@@ -287,7 +327,40 @@ func runSpecGoto(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	if err := wf.Goto(stepVal); err != nil {
+	if err := wf.Goto(cmd.Context(), stepVal); err != nil {
+		return output.WriteError(cmd.ErrOrStderr(), err)
+	}
+	return nil
+}
+
+func runSpecReview(cmd *cobra.Command, args []string) error {
+	if schema, _ := cmd.Flags().GetBool("schema"); schema {
+		s := commandSchema{Input: nil, Output: reviewOutputSchema}
+		return output.Write(cmd.OutOrStdout(), s, "")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("a spec name is required (e.g. spec review my-feature)")
+	}
+	if strings.ContainsAny(args[0], `/\`) {
+		return fmt.Errorf("spec name %q must not contain path separators", args[0])
+	}
+
+	root, err := projectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// The review is a one-shot workflow: it keeps no state, so it can run
+	// alongside an unfinished spec or plan workflow without disturbing it.
+	out := output.New(cmd.OutOrStdout(), globalFields)
+	wf := workflow.New(spec.ReviewSteps(), "", launch.WorkflowConfig(cfg, true), store.NewFileStore(root, "project"), out)
+	wf.SetData("name", args[0])
+
+	if err := wf.Next(cmd.Context()); err != nil {
 		return output.WriteError(cmd.ErrOrStderr(), err)
 	}
 	return nil
@@ -312,7 +385,7 @@ func runSpecStatus(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	steps := spec.Steps()
+	steps := launch.SpecSteps(stateFilePath(dataDir))
 	wf := workflow.New(steps, stateFilePath(dataDir), workflow.Config{}, nil, nil)
 	st := wf.State()
 
@@ -363,9 +436,11 @@ func init() {
 	specNewCmd.Flags().StringP("data", "d", "", `JSON input (e.g. '{"name":"my-feature"}')`)
 	specNewCmd.Flags().String("stdin", "", "Read stdin and store it in workflow data under this key")
 	specNewCmd.Flags().String("file", "", "Read a file at <path> (relative to cwd) and store its contents under the filename's basename (without extension)")
+	specNewCmd.Flags().Bool("quick", false, "Create a quick spec: one question, then the agent writes every section")
+	specNewCmd.Flags().String("on-exists", spec.OnExistsError, "What to do when the spec already exists: error, version (create <name>-2) or overwrite (archive the old spec first)")
 	specGotoCmd.Flags().StringP("data", "d", "", `JSON input (e.g. '{"step":"requirements"}')`)
 	specGotoCmd.Flags().String("stdin", "", "Read stdin and store it in workflow data under this key")
 	specGotoCmd.Flags().String("file", "", "Read a file at <path> (relative to cwd) and store its contents under the filename's basename (without extension)")
 
-	specCmd.AddCommand(specNewCmd, specGotoCmd, specStatusCmd, specStepsCmd)
+	specCmd.AddCommand(specNewCmd, specGotoCmd, specReviewCmd, specStatusCmd, specStepsCmd)
 }
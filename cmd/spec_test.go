@@ -41,6 +41,9 @@ func resetSpecCommandFlags(t *testing.T) {
 		require.NoError(t, specNewCmd.Flags().Set("data", ""))
 		require.NoError(t, specNewCmd.Flags().Set("stdin", ""))
 		require.NoError(t, specNewCmd.Flags().Set("file", ""))
+		require.NoError(t, specNewCmd.Flags().Set("on-exists", "error"))
+		require.NoError(t, specNewCmd.Flags().Set("quick", "false"))
+		require.NoError(t, specGotoCmd.Flags().Set("data", ""))
 	}
 	reset()
 	t.Cleanup(reset)
@@ -133,6 +136,48 @@ func TestSpecNew_ExplicitIDOverridesGeneratedMethod(t *testing.T) {
 	require.FileExists(t, result.SpecPath)
 }
 
+func TestSpecNew_ExistingSpecErrorMentionsOnExists(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandFile(t, dir, "ext-1-billing")
+
+	_, err := runSpecNewForTest(t, "--data", `{"name":"billing","id":"EXT-1"}`)
+	require.ErrorContains(t, err, "already exists")
+	require.ErrorContains(t, err, "--on-exists=version")
+	require.ErrorContains(t, err, "--on-exists=overwrite")
+}
+
+func TestSpecNew_OnExistsVersionCreatesSibling(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandFile(t, dir, "ext-1-billing")
+
+	result, err := runSpecNewForTest(t, "--on-exists", "version", "--data", `{"name":"billing","id":"EXT-1"}`)
+	require.NoError(t, err)
+
+	require.Equal(t, "ext-1-billing-2", result.SpecName)
+	require.FileExists(t, result.SpecPath)
+}
+
+func TestSpecNew_OnExistsOverwriteArchivesOldSpec(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandFile(t, dir, "ext-1-billing")
+	setSpecIdentifierNow(t, time.Date(2026, time.May, 9, 1, 2, 3, 0, time.UTC))
+
+	result, err := runSpecNewForTest(t, "--on-exists", "overwrite", "--data", `{"name":"billing","id":"EXT-1"}`)
+	require.NoError(t, err)
+
+	require.Equal(t, "ext-1-billing", result.SpecName)
+	archived, err := os.ReadFile(filepath.Join(dir, ".spektacular", "specs", "archive", "ext-1-billing-20260509010203.md"))
+	require.NoError(t, err)
+	require.Equal(t, "existing", string(archived))
+
+	current, err := os.ReadFile(result.SpecPath)
+	require.NoError(t, err)
+	require.Contains(t, string(current), "# Feature: ext-1-billing")
+}
+
 func TestSpecNew_ExternalModeWithIDCreatesSpec(t *testing.T) {
 	dir := t.TempDir()
 	t.Chdir(dir)
@@ -158,6 +203,19 @@ func TestSpecNew_ExternalModeRequiresIDWithoutSideEffects(t *testing.T) {
 	require.NoFileExists(t, filepath.Join(dataDir, "state.json"))
 }
 
+func TestSpecNew_BrokenConfigIsFatal(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	dataDir := filepath.Join(dir, ".spektacular")
+	writeSpecCommandConfig(t, dir, "spec:\n\tid_method: counter\n")
+
+	_, err := runSpecNewForTest(t, "--data", `{"name":"billing-export"}`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "config file is present but was not applied")
+	require.Contains(t, err.Error(), filepath.Join(dataDir, "config.yaml")+":2:")
+	require.NoDirExists(t, filepath.Join(dataDir, "specs"))
+}
+
 func TestSpecNew_CounterModeUsesNextValueFromStore(t *testing.T) {
 	dir := t.TempDir()
 	t.Chdir(dir)
@@ -255,3 +313,136 @@ func TestSpecNew_RejectsUnknownConfiguredIDMethod(t *testing.T) {
 	require.NoFileExists(t, filepath.Join(dataDir, "specs", "fixture.md"))
 	require.NoFileExists(t, filepath.Join(dataDir, "state.json"))
 }
+
+func TestSpecNew_UsesNearestProjectAboveWorkingDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeSpecCommandConfig(t, root, "spec:\n  id_method: counter\n")
+	sub := filepath.Join(root, "services", "billing", "internal")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+	t.Chdir(sub)
+
+	result, err := runSpecNewForTest(t, "--data", `{"name":"export"}`)
+	require.NoError(t, err)
+
+	require.Equal(t, filepath.Join(root, ".spektacular", "specs", "000001_export.md"), result.SpecPath)
+	require.NoDirExists(t, filepath.Join(sub, ".spektacular"))
+}
+
+func TestSpecNew_NestedProjectTakesPrecedence(t *testing.T) {
+	root := t.TempDir()
+	writeSpecCommandConfig(t, root, "spec:\n  id_method: counter\n")
+	service := filepath.Join(root, "services", "billing")
+	writeSpecCommandConfig(t, service, "spec:\n  id_method: counter\n")
+	t.Chdir(service)
+
+	result, err := runSpecNewForTest(t, "--data", `{"name":"export"}`)
+	require.NoError(t, err)
+
+	require.Equal(t, filepath.Join(service, ".spektacular", "specs", "000001_export.md"), result.SpecPath)
+	require.NoDirExists(t, filepath.Join(root, ".spektacular", "specs"))
+}
+
+func TestSpecNew_QuickModeRunsSingleStepWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "spec:\n  id_method: counter\n")
+
+	result, err := runSpecNewForTest(t, "--quick", "--data", `{"name":"export"}`)
+	require.NoError(t, err)
+	require.Equal(t, "quick", result.Step)
+	require.Contains(t, result.Instruction, "(inferred — review)")
+	require.Contains(t, result.Instruction, "## Overview", "the quick step embeds the scaffold")
+	require.Contains(t, result.Instruction, `{"step":"finished"}`)
+
+	// Stand in for the agent: commit a filled-in spec, then advance.
+	filled := "# Feature: 000001_export\n\n## Overview\n\nExport billing data.\n\n" +
+		"## Requirements\n\n- [ ] **CSV export**\n\n## Constraints (inferred — review)\n\n- None known\n"
+	require.NoError(t, os.WriteFile(result.SpecPath, []byte(filled), 0o644))
+
+	resetSpecCommandFlags(t)
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"spec", "goto", "--data", `{"step":"finished"}`})
+	require.NoError(t, rootCmd.Execute())
+
+	var finished specCommandResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &finished))
+	require.Equal(t, "finished", finished.Step)
+	require.Contains(t, finished.Instruction, "This was a quick spec")
+	require.NotContains(t, finished.Instruction, "still holds the empty scaffold")
+
+	stdout, _ = setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"spec", "status"})
+	require.NoError(t, rootCmd.Execute())
+
+	var status map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &status))
+	require.EqualValues(t, 3, status["total_steps"])
+	require.Equal(t, "finished", status["current_step"])
+}
+
+func TestSpecNew_WritesThroughSymlinkedSpecDirectory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "")
+	shared := t.TempDir()
+	require.NoError(t, os.Symlink(shared, filepath.Join(dir, ".spektacular", "specs")))
+
+	result, err := runSpecNewForTest(t, "--data", `{"name":"billing","id":"EXT-1"}`)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(shared, "ext-1-billing.md"))
+
+	_, err = runSpecNewForTest(t, "--data", `{"name":"billing","id":"EXT-1"}`)
+	require.ErrorContains(t, err, "already exists", "specs reached through the symlink are seen as existing")
+	require.Equal(t, "ext-1-billing", result.SpecName)
+}
+
+func TestSpecReview_LeavesActiveWorkflowAlone(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	created, err := runSpecNewForTest(t, "--data", `{"name":"billing","id":"EXT-1"}`)
+	require.NoError(t, err)
+	statePath := filepath.Join(dir, ".spektacular", "state.json")
+	before, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+
+	resetSpecCommandFlags(t)
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"spec", "review", created.SpecName})
+	require.NoError(t, rootCmd.Execute())
+
+	var review map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &review))
+	require.Equal(t, "review", review["step"])
+	require.Equal(t, filepath.Join(dir, ".spektacular", "specs", "ext-1-billing.review.md"), review["review_path"])
+
+	after, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+}
+
+func TestSpecReview_RejectsUnknownOrUnsafeNames(t *testing.T) {
+	t.Chdir(t.TempDir())
+	resetSpecCommandFlags(t)
+
+	_, stderr := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"spec", "review", "nosuch"})
+	require.NoError(t, rootCmd.Execute())
+	require.Contains(t, stderr.String(), "not found")
+
+	setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"spec", "review", "../secrets"})
+	require.ErrorContains(t, rootCmd.Execute(), "must not contain path separators")
+}
+
+func TestSpecReview_SuggestsCloseMatch(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandFile(t, dir, "ext-1-billing")
+	resetSpecCommandFlags(t)
+
+	_, stderr := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"spec", "review", "ext-1-biling"})
+	require.NoError(t, rootCmd.Execute())
+	require.Contains(t, stderr.String(), "did you mean ext-1-billing?")
+}
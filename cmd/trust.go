@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jumppad-labs/spektacular/internal/trust"
+	"github.com/spf13/cobra"
+)
+
+// trustAnnotation marks commands that render workflow instructions from the
+// project config, so they refuse to run under an untrusted config.
+const trustAnnotation = "spektacular/requires-trust"
+
+// globalTrust holds the --trust flag, which skips the trust check.
+var globalTrust bool
+
+// trustStore returns the user-level trust record; tests replace it.
+var trustStore = trust.DefaultStore
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Review and trust this project's configuration",
+	Long: `Show the project config values that decide what runs on your behalf and,
+once you confirm, record them as trusted for this project. Workflow commands
+refuse to run until you do, and ask again whenever one of the values changes.
+Set ` + trust.EnvBypass + `=1 or pass --trust to skip the check in CI.`,
+	Args: cobra.NoArgs,
+	RunE: runTrust,
+}
+
+// checkTrust fails a trust-annotated command when the project config sets
+// sensitive values the user has not trusted. An unreadable config passes, so
+// the command itself reports the config error.
+func checkTrust(cmd *cobra.Command) error {
+	if cmd.Annotations[trustAnnotation] == "" || globalTrust || trust.Bypassed() {
+		return nil
+	}
+	if schema, _ := cmd.Flags().GetBool("schema"); schema {
+		return nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	fields := trust.SensitiveFields(cfg)
+	if len(fields) == 0 {
+		return nil
+	}
+	root, err := projectRoot()
+	if err != nil {
+		return err
+	}
+	st, err := trustStore()
+	if err != nil {
+		return err
+	}
+	trusted, err := st.Trusted(root, trust.Fingerprint(fields))
+	if err != nil {
+		return err
+	}
+	if trusted {
+		return nil
+	}
+	return fmt.Errorf("this project's config sets values that decide what runs on your behalf and has not been trusted:\n%s\n"+
+		"Ask the user to review them and run '%s trust', or pass --trust (or set %s=1) in CI",
+		formatTrustFields(fields), cmd.Root().Name(), trust.EnvBypass)
+}
+
+func runTrust(cmd *cobra.Command, _ []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	fields := trust.SensitiveFields(cfg)
+	if len(fields) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "This project's config sets no values that need trusting.")
+		return nil
+	}
+	root, err := projectRoot()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "The config for %s sets values that decide what runs on your behalf:\n%s\n", root, formatTrustFields(fields))
+	fmt.Fprint(cmd.OutOrStdout(), "Trust this configuration? [y/N] ")
+	if !confirmed(cmd.InOrStdin()) {
+		return fmt.Errorf("configuration not trusted")
+	}
+
+	st, err := trustStore()
+	if err != nil {
+		return err
+	}
+	if err := st.Trust(root, trust.Fingerprint(fields)); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Trusted.")
+	return nil
+}
+
+// confirmed reads one line from in and reports whether it is a yes.
+func confirmed(in io.Reader) bool {
+	line, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func formatTrustFields(fields []trust.Field) string {
+	var b strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&b, "  %s: %s\n", f.Name, f.Value)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
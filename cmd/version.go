@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jumppad-labs/spektacular/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, stamped at build time with
+// -ldflags "-X github.com/jumppad-labs/spektacular/cmd.version=... -X ...cmd.commit=... -X ...cmd.buildDate=...".
+var (
+	version   = "dev"
+	commit    = "dev"
+	buildDate = "unknown"
+)
+
+// releasesURL is the GitHub API endpoint queried by `version --check-update`.
+var releasesURL = "https://api.github.com/repos/jumppad-labs/spektacular/releases/latest"
+
+// updateCheckTimeout bounds the release lookup so an unreachable network
+// never stalls the command for long.
+const updateCheckTimeout = 3 * time.Second
+
+// VersionResult is returned by the version command. Verbose fields are only
+// populated with --verbose, update fields only with --check-update.
+type VersionResult struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit,omitempty"`
+	BuildDate       string `json:"build_date,omitempty"`
+	GoVersion       string `json:"go_version,omitempty"`
+	Platform        string `json:"platform,omitempty"`
+	Agent           string `json:"agent,omitempty"`
+	LatestVersion   string `json:"latest_version,omitempty"`
+	UpdateAvailable *bool  `json:"update_available,omitempty"`
+	UpdateError     string `json:"update_error,omitempty"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Args:  cobra.NoArgs,
+	RunE:  runVersion,
+}
+
+func runVersion(cmd *cobra.Command, _ []string) error {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	checkUpdate, _ := cmd.Flags().GetBool("check-update")
+
+	result := VersionResult{Version: version}
+	if verbose {
+		result.Commit = commit
+		result.BuildDate = buildDate
+		result.GoVersion = runtime.Version()
+		result.Platform = runtime.GOOS + "/" + runtime.GOARCH
+		if cfg, err := loadConfig(); err == nil {
+			result.Agent = cfg.Agent
+		}
+	}
+	switch {
+	case checkUpdate && !isReleaseVersion(version):
+		// An unstamped build has no release to compare against.
+		result.UpdateError = "development build: update check skipped"
+	case checkUpdate:
+		ctx, cancel := context.WithTimeout(cmd.Context(), updateCheckTimeout)
+		defer cancel()
+		latest, err := latestRelease(ctx, http.DefaultClient, releasesURL)
+		if err != nil {
+			// A failed lookup is reported, not fatal: the local version is
+			// still useful output.
+			result.UpdateError = err.Error()
+		} else {
+			available := newerVersion(latest, version)
+			result.LatestVersion = latest
+			result.UpdateAvailable = &available
+		}
+	}
+
+	out := output.New(cmd.OutOrStdout(), globalFields)
+	return out.WriteResult(result)
+}
+
+// latestRelease fetches the latest release tag from a GitHub releases/latest
+// endpoint and returns it without any leading "v".
+func latestRelease(ctx context.Context, client *http.Client, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("building release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("checking for updates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checking for updates: unexpected status %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("parsing release response: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("parsing release response: no tag_name")
+	}
+	return strings.TrimPrefix(release.TagName, "v"), nil
+}
+
+// isReleaseVersion reports whether v is a stamped MAJOR.MINOR.PATCH release
+// rather than a development build.
+func isReleaseVersion(v string) bool {
+	_, ok := parseVersion(v)
+	return ok
+}
+
+// newerVersion reports whether latest is a higher dotted version than
+// current. A current version that is not numeric (e.g. "dev") is never
+// reported as outdated.
+func newerVersion(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses "MAJOR.MINOR.PATCH" (with an optional leading "v" and
+// any "-prerelease" suffix ignored).
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "-")
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func init() {
+	versionCmd.Flags().Bool("verbose", false, "Include commit, build date, Go version, platform and configured agent")
+	versionCmd.Flags().Bool("check-update", false, "Query GitHub for a newer release")
+}
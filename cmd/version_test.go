@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersion_VerboseUsesFallbacks(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeSpecCommandConfig(t, dir, "agent: codex\n")
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"version", "--verbose"})
+	require.NoError(t, rootCmd.Execute())
+
+	var result VersionResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	require.Equal(t, version, result.Version)
+	require.Equal(t, "dev", result.Commit)
+	require.Equal(t, "unknown", result.BuildDate)
+	require.NotEmpty(t, result.GoVersion)
+	require.Equal(t, "codex", result.Agent)
+	require.Nil(t, result.UpdateAvailable)
+}
+
+func TestLatestRelease_ParsesFixture(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name":"v0.4.1","name":"Spektacular 0.4.1","draft":false}`))
+	}))
+	defer srv.Close()
+
+	latest, err := latestRelease(context.Background(), srv.Client(), srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, "0.4.1", latest)
+}
+
+func TestLatestRelease_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{name: "bad status", status: http.StatusNotFound, body: `{}`, want: "unexpected status"},
+		{name: "bad json", status: http.StatusOK, body: `not json`, want: "parsing release response"},
+		{name: "missing tag", status: http.StatusOK, body: `{}`, want: "no tag_name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := latestRelease(context.Background(), srv.Client(), srv.URL)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestNewerVersion(t *testing.T) {
+	require.True(t, newerVersion("0.4.0", "0.3.9"))
+	require.True(t, newerVersion("v1.0.0", "0.9.9"))
+	require.False(t, newerVersion("0.3.0", "0.3.0"))
+	require.False(t, newerVersion("0.2.9", "0.3.0"))
+	require.False(t, newerVersion("0.4.0", "dev"))
+	require.False(t, newerVersion("nightly", "0.3.0"))
+}
+
+func TestVersion_CheckUpdateSkipsDevBuild(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("development build queried the releases endpoint")
+	}))
+	defer srv.Close()
+	orig := releasesURL
+	releasesURL = srv.URL
+	t.Cleanup(func() {
+		releasesURL = orig
+		_ = versionCmd.Flags().Set("check-update", "false")
+	})
+
+	stdout, _ := setupImplementCmd(t)
+	rootCmd.SetArgs([]string{"version", "--check-update"})
+	require.NoError(t, rootCmd.Execute())
+
+	var result VersionResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	require.Equal(t, "dev", result.Version)
+	require.Contains(t, result.UpdateError, "development build")
+	require.Nil(t, result.UpdateAvailable)
+}
+
+// TestVersion_StampedBuildReportsVersion builds the binary with the same
+// -ldflags the Makefile and release pipeline use and checks they take effect.
+func TestVersion_StampedBuildReportsVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the binary")
+	}
+	bin := filepath.Join(t.TempDir(), "spektacular")
+	const pkg = "github.com/jumppad-labs/spektacular/cmd"
+	build := exec.Command("go", "build", "-o", bin,
+		"-ldflags", "-X "+pkg+".version=9.8.7 -X "+pkg+".commit=abc1234 -X "+pkg+".buildDate=2026-01-02T03:04:05Z",
+		"github.com/jumppad-labs/spektacular")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	run := exec.Command(bin, "version", "--verbose")
+	run.Dir = t.TempDir()
+	out, err = run.Output()
+	require.NoError(t, err)
+
+	var result VersionResult
+	require.NoError(t, json.Unmarshal(out, &result))
+	require.Equal(t, "9.8.7", result.Version)
+	require.Equal(t, "abc1234", result.Commit)
+	require.Equal(t, "2026-01-02T03:04:05Z", result.BuildDate)
+}
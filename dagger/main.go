@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
@@ -15,6 +16,17 @@ var arches = []string{"amd64", "arm64"}
 var owner = "jumppad-labs"
 var repo = "spektacular"
 
+// buildInfoPkg is the package holding the version, commit and build date
+// stamped into release binaries; see the Makefile's LDFLAGS.
+var buildInfoPkg = "github.com/jumppad-labs/" + repo + "/cmd"
+
+// ldflags returns the -ldflags value that stamps version, commit and build
+// date into the binary.
+func ldflags(version, sha string, built time.Time) string {
+	return fmt.Sprintf("-X %[1]s.version=%[2]s -X %[1]s.commit=%[3]s -X %[1]s.buildDate=%[4]s",
+		buildInfoPkg, version, sha, built.UTC().Format(time.RFC3339))
+}
+
 func New() *Spektacular {
 	return &Spektacular{}
 }
@@ -134,6 +146,8 @@ func (d *Spektacular) Build(
 		WithWorkdir("/src").
 		WithMountedCache("/go/pkg/mod", d.goCache())
 
+	flags := ldflags(version, sha, time.Now())
+
 	for _, goos := range oses {
 		for _, goarch := range arches {
 			fmt.Println("Build for", goos, goarch, "...")
@@ -149,7 +163,7 @@ func (d *Spektacular) Build(
 				WithExec([]string{
 					"go", "build",
 					"-o", path,
-					"-ldflags", fmt.Sprintf("-X main.version=%s -X main.sha=%s", version, sha),
+					"-ldflags", flags,
 				}).
 				Sync(ctx)
 
@@ -10,6 +10,8 @@ type bobAgent struct{}
 
 func (bobAgent) Name() string { return "bob" }
 
+func (bobAgent) InstructionFiles() []string { return []string{"AGENTS.md"} }
+
 func (bobAgent) Install(projectPath string, cfg config.Config, out io.Writer) error {
 	if err := installWorkflowSkills(projectPath, ".bob/skills", cfg, out); err != nil {
 		return err
@@ -10,6 +10,8 @@ type claudeAgent struct{}
 
 func (claudeAgent) Name() string { return "claude" }
 
+func (claudeAgent) InstructionFiles() []string { return []string{"CLAUDE.md"} }
+
 func (claudeAgent) Install(projectPath string, cfg config.Config, out io.Writer) error {
 	// Claude Code surfaces installed skills directly in its slash-command menu
 	// (e.g. `/spek-new`), so a separate command wrapper would be redundant. Other
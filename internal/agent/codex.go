@@ -10,6 +10,8 @@ type codexAgent struct{}
 
 func (codexAgent) Name() string { return "codex" }
 
+func (codexAgent) InstructionFiles() []string { return []string{"AGENTS.md"} }
+
 func (codexAgent) Install(projectPath string, cfg config.Config, out io.Writer) error {
 	return installWorkflowSkills(projectPath, ".agents/skills", cfg, out)
 }
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// instructionFileNames are the project-root house-rules files agents commonly
+// load on their own. init reports which of them exist and whether the chosen
+// agent will actually read them.
+var instructionFileNames = []string{"AGENTS.md", "CLAUDE.md"}
+
+// instructionReader is implemented by agents that natively load one or more
+// project-root instruction files.
+type instructionReader interface {
+	InstructionFiles() []string
+}
+
+// InstructionFile is an agent instructions file found at the project root.
+type InstructionFile struct {
+	Name    string // file name relative to the project root
+	Honored bool   // true when the agent reads the file natively
+}
+
+// DetectInstructionFiles returns the instruction files present at
+// projectPath, each marked with whether a reads it natively. Agents that do
+// not implement InstructionFiles are treated as reading none of them.
+func DetectInstructionFiles(projectPath string, a Agent) []InstructionFile {
+	var native []string
+	if r, ok := a.(instructionReader); ok {
+		native = r.InstructionFiles()
+	}
+
+	var found []InstructionFile
+	for _, name := range instructionFileNames {
+		info, err := os.Stat(filepath.Join(projectPath, name))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		found = append(found, InstructionFile{Name: name, Honored: slices.Contains(native, name)})
+	}
+	return found
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeInstructionFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, n := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, n), []byte("# rules\n"), 0644))
+	}
+}
+
+func TestDetectInstructionFiles_None(t *testing.T) {
+	require.Empty(t, DetectInstructionFiles(t.TempDir(), claudeAgent{}))
+}
+
+func TestDetectInstructionFiles_MarksNativeFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeInstructionFiles(t, dir, "CLAUDE.md", "AGENTS.md")
+
+	require.Equal(t, []InstructionFile{
+		{Name: "AGENTS.md", Honored: false},
+		{Name: "CLAUDE.md", Honored: true},
+	}, DetectInstructionFiles(dir, claudeAgent{}))
+
+	require.Equal(t, []InstructionFile{
+		{Name: "AGENTS.md", Honored: true},
+		{Name: "CLAUDE.md", Honored: false},
+	}, DetectInstructionFiles(dir, codexAgent{}))
+
+	require.Equal(t, []InstructionFile{
+		{Name: "AGENTS.md", Honored: true},
+		{Name: "CLAUDE.md", Honored: false},
+	}, DetectInstructionFiles(dir, bobAgent{}))
+}
+
+func TestDetectInstructionFiles_AgentWithoutNativeFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeInstructionFiles(t, dir, "AGENTS.md")
+
+	require.Equal(t, []InstructionFile{{Name: "AGENTS.md", Honored: false}},
+		DetectInstructionFiles(dir, fakeAgent{name: "fake"}))
+}
+
+func TestDetectInstructionFiles_IgnoresDirectories(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "CLAUDE.md"), 0755))
+
+	require.Empty(t, DetectInstructionFiles(dir, claudeAgent{}))
+}
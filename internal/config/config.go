@@ -1,22 +1,36 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 var envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
 
+// yamlLinePattern extracts the line number yaml.v3 embeds in its error text
+// ("yaml: line 7: did not find expected key").
+var yamlLinePattern = regexp.MustCompile(`line (\d+): `)
+
 const (
 	SpecIDMethodTimestamp = "timestamp"
 	SpecIDMethodCounter   = "counter"
 	SpecIDMethodExternal  = "external"
 )
 
+// Question styles for protocol.question_style: the marker syntax agents use
+// to ask questions and signal that a step is finished.
+const (
+	QuestionStyleComment = "comment"
+	QuestionStyleFence   = "fence"
+)
+
 // ProviderFile is the only storage provider this release ships. The provider
 // field on the spec, plan, and knowledge sections names a backend; today it
 // must always be this value.
@@ -34,11 +48,74 @@ const (
 	// DefaultKnowledgeLocation is the project-relative location of the
 	// synthesised default knowledge source.
 	DefaultKnowledgeLocation = ".spektacular/knowledge"
+	// AnswersDir is the project-relative directory answers too large for the
+	// resume prompt are written to when answer_files is enabled.
+	AnswersDir = ".spektacular/answers"
 )
 
-// DebugConfig holds debug logging configuration.
+// DebugConfig holds debug logging configuration. SplitSteps writes each
+// step of a multi-step run to its own log file alongside the combined one.
 type DebugConfig struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled    bool `yaml:"enabled"`
+	SplitSteps bool `yaml:"split_steps"`
+}
+
+// AttachmentsConfig caps how much file content is inlined into an agent
+// prompt. Zero values fall back to the runner's defaults.
+type AttachmentsConfig struct {
+	MaxFileBytes  int `yaml:"max_file_bytes,omitempty"`
+	MaxTotalBytes int `yaml:"max_total_bytes,omitempty"`
+}
+
+// ToolAccessConfig lists project-root-relative paths the agent's file tools
+// (Read, Grep, Glob) must not touch. A nil Ignore uses the runner's defaults;
+// an explicit empty list turns the restriction off.
+type ToolAccessConfig struct {
+	Ignore []string `yaml:"ignore"`
+}
+
+// IsZero reports whether the section is unset, so a written config omits it
+// only while Ignore is nil; an explicit empty list is kept as "ignore: []".
+func (c ToolAccessConfig) IsZero() bool {
+	return c.Ignore == nil
+}
+
+// LargeWritesConfig flags agent Write tool calls with unusually large
+// content. A zero ThresholdBytes falls back to the runner's default. Confirm
+// adds a follow-up turn asking the agent to confirm the write was intended.
+type LargeWritesConfig struct {
+	ThresholdBytes int  `yaml:"threshold_bytes,omitempty"`
+	Confirm        bool `yaml:"confirm,omitempty"`
+}
+
+// LoopDetectionConfig sets how many times an agent may repeat the same
+// question or turn text within a step before the step is stopped. Zero uses
+// the runner's default.
+type LoopDetectionConfig struct {
+	MaxRepeats int `yaml:"max_repeats,omitempty"`
+}
+
+// AnswerFilesConfig moves long answers out of the resume prompt. An answer
+// larger than ThresholdBytes is written under AnswersDir and the agent is
+// asked to read it from there, so it needs Read access. Zero (the default)
+// always sends answers inline.
+type AnswerFilesConfig struct {
+	ThresholdBytes int `yaml:"threshold_bytes,omitempty"`
+}
+
+// AgentEnvConfig adjusts the environment agent processes inherit. Set adds or
+// overrides variables (values may use ${VAR} like the rest of the file);
+// Remove strips variables by name, where a trailing "*" matches a prefix
+// (e.g. "AWS_*").
+type AgentEnvConfig struct {
+	Set    map[string]string `yaml:"set,omitempty"`
+	Remove []string          `yaml:"remove,omitempty"`
+}
+
+// ProtocolConfig selects the marker syntax agents use to talk to the runner.
+// An empty QuestionStyle means QuestionStyleComment.
+type ProtocolConfig struct {
+	QuestionStyle string `yaml:"question_style,omitempty"`
 }
 
 // SpecConfig holds configuration for specification creation. It names a
@@ -48,6 +125,9 @@ type SpecConfig struct {
 	Provider string         `yaml:"provider"`
 	IDMethod string         `yaml:"id_method"`
 	Config   FileSpecConfig `yaml:"config"`
+	// AppendQA appends the clarifying questions and answers gathered while
+	// writing the spec as a "## Clarifications" section.
+	AppendQA bool `yaml:"append_qa,omitempty"`
 }
 
 // FileSpecConfig is the file-provider configuration for the spec section.
@@ -93,6 +173,20 @@ type Config struct {
 	Spec      SpecConfig      `yaml:"spec"`
 	Plan      PlanConfig      `yaml:"plan"`
 	Knowledge KnowledgeConfig `yaml:"knowledge"`
+	// Attachments is omitted from a written config until a limit is set.
+	Attachments AttachmentsConfig `yaml:"attachments,omitempty"`
+	// ToolAccess is omitted from a written config until Ignore is set.
+	ToolAccess ToolAccessConfig `yaml:"tool_access,omitempty"`
+	// Protocol is omitted from a written config until a style is set.
+	Protocol ProtocolConfig `yaml:"protocol,omitempty"`
+	// LargeWrites is omitted from a written config until it is set.
+	LargeWrites LargeWritesConfig `yaml:"large_writes,omitempty"`
+	// LoopDetection is omitted from a written config until it is set.
+	LoopDetection LoopDetectionConfig `yaml:"loop_detection,omitempty"`
+	// AgentEnv is omitted from a written config until it is set.
+	AgentEnv AgentEnvConfig `yaml:"agent_env,omitempty"`
+	// AnswerFiles is omitted from a written config until it is set.
+	AnswerFiles AnswerFilesConfig `yaml:"answer_files,omitempty"`
 }
 
 // NewDefault returns a Config populated with default values.
@@ -143,7 +237,7 @@ func FromYAMLFile(path string) (Config, error) {
 
 	cfg := NewDefault()
 	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
-		return Config{}, fmt.Errorf("parsing config file %s: %w", path, err)
+		return Config{}, newParseError(path, string(raw), err)
 	}
 	if err := cfg.Validate(); err != nil {
 		return Config{}, fmt.Errorf("validating config file %s: %w", path, err)
@@ -151,6 +245,65 @@ func FromYAMLFile(path string) (Config, error) {
 	return cfg, nil
 }
 
+// ParseError reports a config file that exists but is not valid YAML for the
+// Config schema. It carries enough location detail to point the user at the
+// offending line rather than yaml.v3's bare "line 7: ..." message.
+type ParseError struct {
+	Path string // config file path
+	Line int    // 1-based line number; 0 when yaml did not report one
+	Text string // the offending line as written in the file
+	Err  error  // underlying yaml error
+}
+
+func (e *ParseError) Error() string {
+	msg := yamlMessage(e.Err)
+	if e.Line == 0 {
+		return fmt.Sprintf("parsing config file %s: %s", e.Path, msg)
+	}
+	// Tabs are shown as single spaces so the caret lines up; a tab is also the
+	// most common culprit, so point at it when present.
+	shown := strings.ReplaceAll(e.Text, "\t", " ")
+	col := strings.IndexByte(e.Text, '\t')
+	if col < 0 {
+		col = len(e.Text) - len(strings.TrimLeft(e.Text, " "))
+	}
+	gutter := fmt.Sprintf("  %d | ", e.Line)
+	return fmt.Sprintf("parsing config file %s:%d: %s\n%s%s\n%s^",
+		e.Path, e.Line, msg, gutter, shown, strings.Repeat(" ", len(gutter)+col))
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// newParseError wraps a yaml.Unmarshal error with the file path and the text
+// of the line yaml reported. raw is the file content before env expansion, so
+// the quoted line matches what the user sees in their editor.
+func newParseError(path, raw string, err error) *ParseError {
+	pe := &ParseError{Path: path, Err: err}
+	m := yamlLinePattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return pe
+	}
+	line, convErr := strconv.Atoi(m[1])
+	lines := strings.Split(raw, "\n")
+	if convErr != nil || line < 1 || line > len(lines) {
+		return pe
+	}
+	pe.Line = line
+	pe.Text = strings.TrimRight(lines[line-1], "\r")
+	return pe
+}
+
+// yamlMessage strips yaml.v3's "yaml: " and "line N: " prefixes, which
+// ParseError already renders, leaving just the description of the problem.
+func yamlMessage(err error) string {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		return yamlLinePattern.ReplaceAllString(strings.TrimSpace(typeErr.Errors[0]), "")
+	}
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	return yamlLinePattern.ReplaceAllString(msg, "")
+}
+
 // Validate checks whether the config contains supported values.
 func (c Config) Validate() error {
 	if err := c.Spec.Validate(); err != nil {
@@ -162,6 +315,90 @@ func (c Config) Validate() error {
 	if err := c.Knowledge.Validate(); err != nil {
 		return err
 	}
+	if err := c.Attachments.Validate(); err != nil {
+		return err
+	}
+	readable := []string{c.Spec.Config.Directory, c.Plan.Config.Directory}
+	if c.AnswerFiles.ThresholdBytes > 0 {
+		readable = append(readable, AnswersDir)
+	}
+	if err := c.ToolAccess.Validate(readable...); err != nil {
+		return err
+	}
+	if err := c.Protocol.Validate(); err != nil {
+		return err
+	}
+	if c.LargeWrites.ThresholdBytes < 0 {
+		return fmt.Errorf("large_writes.threshold_bytes must not be negative")
+	}
+	if c.LoopDetection.MaxRepeats < 0 || c.LoopDetection.MaxRepeats == 1 {
+		return fmt.Errorf("loop_detection.max_repeats must be at least 2, or 0 for the default")
+	}
+	if err := c.AgentEnv.Validate(); err != nil {
+		return err
+	}
+	if c.AnswerFiles.ThresholdBytes < 0 {
+		return fmt.Errorf("answer_files.threshold_bytes must not be negative")
+	}
+	return nil
+}
+
+// Validate checks that every variable name is non-empty and free of "=".
+func (c AgentEnvConfig) Validate() error {
+	for name := range c.Set {
+		if name == "" || strings.Contains(name, "=") {
+			return fmt.Errorf("agent_env.set has an invalid variable name %q", name)
+		}
+	}
+	for _, name := range c.Remove {
+		if name == "" || name == "*" || strings.Contains(name, "=") {
+			return fmt.Errorf("agent_env.remove has an invalid variable name %q", name)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the question style is supported.
+func (c ProtocolConfig) Validate() error {
+	switch c.QuestionStyle {
+	case "", QuestionStyleComment, QuestionStyleFence:
+		return nil
+	}
+	return fmt.Errorf("protocol.question_style %q is not supported (use %q or %q)", c.QuestionStyle, QuestionStyleComment, QuestionStyleFence)
+}
+
+// Validate checks that every ignored path is a non-empty relative path that
+// does not hide any of the given directories, which workflows must be able to
+// read.
+func (c ToolAccessConfig) Validate(readable ...string) error {
+	for _, p := range c.Ignore {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("tool_access.ignore entries must not be empty")
+		}
+		if filepath.IsAbs(p) {
+			return fmt.Errorf("tool_access.ignore entry %q must be relative to the project root", p)
+		}
+		ignored := filepath.Clean(p)
+		for _, dir := range readable {
+			if dir == "" {
+				continue
+			}
+			if rel, err := filepath.Rel(ignored, filepath.Clean(dir)); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("tool_access.ignore entry %q would hide %s, which workflows need to read", p, dir)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks that the attachment limits are not negative.
+func (c AttachmentsConfig) Validate() error {
+	if c.MaxFileBytes < 0 {
+		return fmt.Errorf("attachments.max_file_bytes must not be negative")
+	}
+	if c.MaxTotalBytes < 0 {
+		return fmt.Errorf("attachments.max_total_bytes must not be negative")
+	}
 	return nil
 }
 
@@ -174,6 +411,9 @@ func (c SpecConfig) Validate() error {
 	if c.Config.Directory == "" {
 		return fmt.Errorf("spec.config.directory must not be empty")
 	}
+	if err := projectRelative("spec.config.directory", c.Config.Directory); err != nil {
+		return err
+	}
 	switch c.IDMethod {
 	case "", SpecIDMethodTimestamp, SpecIDMethodCounter, SpecIDMethodExternal:
 	default:
@@ -182,6 +422,17 @@ func (c SpecConfig) Validate() error {
 	return nil
 }
 
+// projectRelative rejects a directory that is absolute or climbs out of the
+// project root. Workflows reach specs and plans through a store rooted at the
+// project, so a directory elsewhere must be linked in with a symlink instead.
+func projectRelative(field, dir string) error {
+	clean := filepath.Clean(dir)
+	if filepath.IsAbs(dir) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s %q must be inside the project root; to keep files elsewhere (e.g. a shared docs repo), symlink them into a project directory instead", field, dir)
+	}
+	return nil
+}
+
 // Validate checks whether the plan config names a supported provider and
 // carries valid provider settings.
 func (c PlanConfig) Validate() error {
@@ -191,6 +442,9 @@ func (c PlanConfig) Validate() error {
 	if c.Config.Directory == "" {
 		return fmt.Errorf("plan.config.directory must not be empty")
 	}
+	if err := projectRelative("plan.config.directory", c.Config.Directory); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -237,18 +491,56 @@ func (c KnowledgeConfig) WithDefaults(projectRoot string) KnowledgeConfig {
 	}
 }
 
-// ToYAMLFile writes the Config to a YAML file.
+// ToYAMLFile writes the Config to a YAML file, creating parent directories as
+// needed. The write is atomic — the YAML goes to a temporary file in the same
+// directory that is then renamed over path — so a crash cannot leave a
+// half-written config. Overwriting keeps the existing file's permissions.
 func (c Config) ToYAMLFile(path string) error {
 	data, err := yaml.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("marshalling config: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0644); err != nil {
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating config directory %s: %w", dir, err)
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, tempPattern(path))
+	if err != nil {
+		return fmt.Errorf("writing config file %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename has succeeded
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing config file %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing config file %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing config file %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
 		return fmt.Errorf("writing config file %s: %w", path, err)
 	}
 	return nil
 }
 
+// tempPattern is the os.CreateTemp pattern for an atomic write of path: a
+// hidden sibling such as ".config.yaml.tmp-123456".
+func tempPattern(path string) string {
+	return "." + filepath.Base(path) + ".tmp-*"
+}
+
 // expandEnvVars replaces ${VAR} patterns in s with the current environment values.
 func expandEnvVars(s string) string {
 	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
@@ -80,6 +80,70 @@ func TestToYAMLFile_RoundTrip(t *testing.T) {
 	require.Equal(t, cfg.Spec.IDMethod, loaded.Spec.IDMethod)
 }
 
+func TestToYAMLFile_CreatesMissingParentDirectories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "home", ".config", "spektacular", "config.yaml")
+
+	require.NoError(t, NewDefault().ToYAMLFile(path))
+
+	loaded, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, NewDefault().Command, loaded.Command)
+}
+
+func TestToYAMLFile_LeavesNoTempFileBehind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	require.NoError(t, NewDefault().ToYAMLFile(path))
+
+	leftovers, err := filepath.Glob(filepath.Join(dir, tempPattern(path)))
+	require.NoError(t, err)
+	require.Empty(t, leftovers)
+}
+
+func TestToYAMLFile_FailedRenameLeavesTargetAndNoTempFile(t *testing.T) {
+	dir := t.TempDir()
+	// A non-empty directory at the target path makes the final rename fail
+	// after the temp file has been fully written.
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "occupied"), 0755))
+
+	err := NewDefault().ToYAMLFile(path)
+	require.Error(t, err)
+	require.DirExists(t, filepath.Join(path, "occupied"))
+
+	leftovers, err := filepath.Glob(filepath.Join(dir, tempPattern(path)))
+	require.NoError(t, err)
+	require.Empty(t, leftovers)
+}
+
+func TestToYAMLFile_PreservesExistingPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("command: old\n"), 0600))
+
+	cfg := NewDefault()
+	cfg.Command = "new"
+	require.NoError(t, cfg.ToYAMLFile(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "new", loaded.Command)
+}
+
+func TestToYAMLFile_NewFileUsesDefaultPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	require.NoError(t, NewDefault().ToYAMLFile(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
 // Criterion 1: spec, plan, and knowledge each round-trip a provider plus
 // config block through YAML, with knowledge carrying multiple independently
 // configured sources.
@@ -208,6 +272,73 @@ func TestFromYAMLFile_EmptySpecDirectoryReturnsError(t *testing.T) {
 	require.Contains(t, err.Error(), "spec.config.directory")
 }
 
+func TestFromYAMLFile_AttachmentLimits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("attachments:\n  max_file_bytes: 1024\n"), 0644))
+
+	cfg, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 1024, cfg.Attachments.MaxFileBytes)
+	require.Zero(t, cfg.Attachments.MaxTotalBytes)
+
+	require.NoError(t, os.WriteFile(path, []byte("attachments:\n  max_total_bytes: -1\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, "attachments.max_total_bytes")
+}
+
+func TestToYAMLFile_ToolAccessIgnoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	require.NoError(t, NewDefault().ToYAMLFile(path))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "tool_access")
+	loaded, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Nil(t, loaded.ToolAccess.Ignore)
+
+	cfg := NewDefault()
+	cfg.ToolAccess.Ignore = []string{}
+	require.NoError(t, cfg.ToYAMLFile(path))
+	loaded, err = FromYAMLFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, loaded.ToolAccess.Ignore)
+	require.Empty(t, loaded.ToolAccess.Ignore)
+}
+
+func TestFromYAMLFile_ToolAccessIgnore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("tool_access:\n  ignore: [.spektacular/logs, tmp]\n"), 0644))
+
+	cfg, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{".spektacular/logs", "tmp"}, cfg.ToolAccess.Ignore)
+
+	require.NoError(t, os.WriteFile(path, []byte("tool_access:\n  ignore: [.spektacular]\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, `tool_access.ignore entry ".spektacular" would hide .spektacular/specs`)
+
+	require.NoError(t, os.WriteFile(path, []byte("tool_access:\n  ignore: [/var/log]\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, "must be relative to the project root")
+}
+
+func TestFromYAMLFile_ProtocolQuestionStyle(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("protocol:\n  question_style: fence\n"), 0644))
+
+	cfg, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, QuestionStyleFence, cfg.Protocol.QuestionStyle)
+
+	require.NoError(t, os.WriteFile(path, []byte("protocol:\n  question_style: xml\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, `protocol.question_style "xml" is not supported`)
+}
+
 // Criterion 3: a knowledge source missing its required location is rejected.
 func TestKnowledgeConfig_ValidateRejectsMissingLocation(t *testing.T) {
 	knowledge := KnowledgeConfig{
@@ -234,3 +365,121 @@ func TestKnowledgeConfig_ValidateRejectsDuplicateScope(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "more than once")
 }
+
+// Malformed YAML is reported with the file path, the offending line's text
+// and a caret, rather than yaml.v3's bare "line N" message.
+func TestFromYAMLFile_ParseErrorsIncludeLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		line     int
+		contains []string
+	}{
+		{
+			name:     "tab indentation",
+			yaml:     "command: spektacular\nspec:\n\tprovider: file\n",
+			line:     3,
+			contains: []string{":3:", "found character that cannot start any token", "3 |  provider: file", "\n      ^"},
+		},
+		{
+			name:     "wrong indentation",
+			yaml:     "command: spektacular\nspec:\n  provider: file\n   id_method: counter\n",
+			line:     4,
+			contains: []string{":4:", "mapping values are not allowed in this context", "4 |    id_method: counter", "\n         ^"},
+		},
+		{
+			name:     "wrong type",
+			yaml:     "debug:\n  enabled: sometimes\n",
+			line:     2,
+			contains: []string{":2:", "cannot unmarshal !!str `sometimes` into bool", "2 |   enabled: sometimes"},
+		},
+		{
+			name:     "unterminated flow sequence",
+			yaml:     "command: [spektacular\n",
+			line:     1,
+			contains: []string{":1:", "1 | command: [spektacular"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.yaml), 0644))
+
+			_, err := FromYAMLFile(path)
+			require.Error(t, err)
+
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, tc.line, parseErr.Line)
+			require.Contains(t, err.Error(), path)
+			require.NotContains(t, err.Error(), "yaml: line")
+			for _, want := range tc.contains {
+				require.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestFromYAMLFile_LoopDetection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("loop_detection:\n  max_repeats: 5\n"), 0644))
+
+	cfg, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 5, cfg.LoopDetection.MaxRepeats)
+
+	require.NoError(t, os.WriteFile(path, []byte("loop_detection:\n  max_repeats: 1\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, "loop_detection.max_repeats")
+}
+
+func TestFromYAMLFile_AgentEnv(t *testing.T) {
+	t.Setenv("CORP_PROXY", "http://proxy:3128")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	body := "agent_env:\n  set:\n    HTTPS_PROXY: ${CORP_PROXY}\n  remove: [AWS_*]\n"
+	require.NoError(t, os.WriteFile(path, []byte(body), 0644))
+
+	cfg, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"HTTPS_PROXY": "http://proxy:3128"}, cfg.AgentEnv.Set)
+	require.Equal(t, []string{"AWS_*"}, cfg.AgentEnv.Remove)
+
+	require.NoError(t, os.WriteFile(path, []byte("agent_env:\n  remove: [\"*\"]\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, "agent_env.remove")
+}
+
+func TestFromYAMLFile_DirectoriesMustStayInsideProject(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte("spec:\n  config:\n    directory: /shared/docs/specs\n"), 0644))
+	_, err := FromYAMLFile(path)
+	require.ErrorContains(t, err, `spec.config.directory "/shared/docs/specs" must be inside the project root`)
+	require.ErrorContains(t, err, "symlink")
+
+	require.NoError(t, os.WriteFile(path, []byte("plan:\n  config:\n    directory: ../plans\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, "plan.config.directory")
+}
+
+func TestFromYAMLFile_AnswerFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("answer_files:\n  threshold_bytes: 8192\n"), 0644))
+
+	cfg, err := FromYAMLFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 8192, cfg.AnswerFiles.ThresholdBytes)
+
+	require.NoError(t, os.WriteFile(path, []byte("answer_files:\n  threshold_bytes: -1\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, "answer_files.threshold_bytes")
+
+	require.NoError(t, os.WriteFile(path, []byte("answer_files:\n  threshold_bytes: 8192\ntool_access:\n  ignore: [.spektacular/answers]\n"), 0644))
+	_, err = FromYAMLFile(path)
+	require.ErrorContains(t, err, "would hide .spektacular/answers")
+}
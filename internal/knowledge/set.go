@@ -4,6 +4,7 @@
 package knowledge
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -74,10 +75,14 @@ func NewSet(cfg config.Config, projectRoot string) (*Set, error) {
 // Search runs the query against every source in configured order and
 // concatenates the scope-tagged hits. It performs no ranking or dedup. If any
 // source errors, Search returns an error naming that source and no results.
-func (s *Set) Search(query string) ([]store.Hit, error) {
+// A cancelled ctx stops the search before the next source.
+func (s *Set) Search(ctx context.Context, query string) ([]store.Hit, error) {
 	var hits []store.Hit
 	for _, src := range s.sources {
-		h, err := src.store.Search(query)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		h, err := src.store.Search(ctx, query)
 		if err != nil {
 			return nil, fmt.Errorf("searching knowledge source %q: %w", src.scope, err)
 		}
@@ -107,11 +112,11 @@ func (s *Set) Write(scope, path string, content []byte) error {
 
 // List recursively enumerates every file entry across every configured scope,
 // concatenated in configured order. Subdirectories are descended into; only
-// file locators are emitted.
-func (s *Set) List() ([]Entry, error) {
+// file locators are emitted. A cancelled ctx stops the walk.
+func (s *Set) List(ctx context.Context) ([]Entry, error) {
 	var entries []Entry
 	for _, src := range s.sources {
-		files, err := listFiles(src.store, "")
+		files, err := listFiles(ctx, src.store, "")
 		if err != nil {
 			return nil, fmt.Errorf("listing knowledge source %q: %w", src.scope, err)
 		}
@@ -144,7 +149,10 @@ func (s *Set) byScope(scope string) (scopedStore, error) {
 // listFiles recursively walks a store from dir, returning store-relative file
 // locators. Directories are descended into via Store.List, which stays one
 // level deep — the recursion lives here in the knowledge layer.
-func listFiles(st store.Store, dir string) ([]string, error) {
+func listFiles(ctx context.Context, st store.Store, dir string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	children, err := st.List(dir)
 	if err != nil {
 		return nil, err
@@ -156,7 +164,7 @@ func listFiles(st store.Store, dir string) ([]string, error) {
 			childPath = dir + "/" + child.Name
 		}
 		if child.IsDir {
-			sub, err := listFiles(st, childPath)
+			sub, err := listFiles(ctx, st, childPath)
 			if err != nil {
 				return nil, err
 			}
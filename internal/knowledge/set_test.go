@@ -57,7 +57,7 @@ func twoScopeSet(t *testing.T) (set *Set, projectDir, teamDir string) {
 func TestSet_FansAcrossScopesIncludingSubdirs(t *testing.T) {
 	set, _, _ := twoScopeSet(t)
 
-	entries, err := set.List()
+	entries, err := set.List(t.Context())
 	require.NoError(t, err)
 	require.ElementsMatch(t, []Entry{
 		{Scope: "project", Path: "readme.md"},
@@ -70,7 +70,7 @@ func TestSet_FansAcrossScopesIncludingSubdirs(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte("team overview of the system\n"), data)
 
-	hits, err := set.Search("compass")
+	hits, err := set.Search(t.Context(), "compass")
 	require.NoError(t, err)
 	scopes := map[string]bool{}
 	for _, h := range hits {
@@ -98,7 +98,7 @@ func TestSet_OverlappingEntriesTaggedPerScope(t *testing.T) {
 	set, err := NewSet(cfg, t.TempDir())
 	require.NoError(t, err)
 
-	hits, err := set.Search("compass")
+	hits, err := set.Search(t.Context(), "compass")
 	require.NoError(t, err)
 	hitScopes := map[string]bool{}
 	for _, h := range hits {
@@ -107,7 +107,7 @@ func TestSet_OverlappingEntriesTaggedPerScope(t *testing.T) {
 	require.True(t, hitScopes["project"], "compass hit should be tagged project")
 	require.True(t, hitScopes["team"], "compass hit should be tagged team")
 
-	entries, err := set.List()
+	entries, err := set.List(t.Context())
 	require.NoError(t, err)
 	require.ElementsMatch(t, []Entry{
 		{Scope: "project", Path: "notes/topic.md"},
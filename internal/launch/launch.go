@@ -0,0 +1,152 @@
+// Package launch prepares workflow runs. It holds the decisions the CLI
+// commands and the public pkg/spektacular API must make identically — the
+// workflow configuration derived from config.yaml, how a new spec is named
+// and what happens to one it replaces, which step list a persisted spec
+// workflow follows, and the documents a plan or implement workflow needs
+// before it starts — so the two entry points cannot drift apart.
+package launch
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/jumppad-labs/spektacular/internal/steps/implement"
+	"github.com/jumppad-labs/spektacular/internal/steps/spec"
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/jumppad-labs/spektacular/internal/suggest"
+	"github.com/jumppad-labs/spektacular/internal/workflow"
+)
+
+// WorkflowConfig returns the workflow.Config for a project configured by cfg.
+func WorkflowConfig(cfg config.Config, dryRun bool) workflow.Config {
+	return workflow.Config{
+		Command:      cfg.Command,
+		DryRun:       dryRun,
+		SpecDir:      cfg.Spec.Config.Directory,
+		PlanDir:      cfg.Plan.Config.Directory,
+		SpecAppendQA: cfg.Spec.AppendQA,
+	}
+}
+
+// SpecRequest describes a new spec workflow.
+type SpecRequest struct {
+	Name string
+	ID   string // optional explicit id prefix
+	// OnExists is one of the spec.OnExists* policies; empty means
+	// spec.OnExistsError.
+	OnExists string
+	Quick    bool
+	// DryRun resolves the name without archiving a spec being overwritten.
+	DryRun bool
+	Now    func() time.Time // defaults to time.Now
+}
+
+// SpecStart is a resolved new spec workflow.
+type SpecStart struct {
+	Name  string
+	Mode  string // "" or spec.ModeQuick
+	Steps []workflow.StepConfig
+	// Archived is the store-relative path the replaced spec was copied to
+	// when OnExists is spec.OnExistsOverwrite and the spec existed.
+	Archived string
+}
+
+// Spec resolves the canonical name and step list for a new spec workflow.
+// When the request overwrites an existing spec, the old one is archived
+// first (unless DryRun is set). An existing spec under OnExistsError yields
+// an error wrapping spec.ErrSpecExists.
+func Spec(st store.Store, cfg config.Config, req SpecRequest) (SpecStart, error) {
+	now := req.Now
+	if now == nil {
+		now = time.Now
+	}
+	resolved, err := spec.ResolveIdentifier(spec.IdentifierRequest{
+		Name:     req.Name,
+		ID:       req.ID,
+		Method:   cfg.Spec.IDMethod,
+		SpecDir:  cfg.Spec.Config.Directory,
+		Store:    st,
+		Now:      now,
+		OnExists: req.OnExists,
+	})
+	if err != nil {
+		return SpecStart{}, err
+	}
+
+	start := SpecStart{Name: resolved.Name}
+	if req.Quick {
+		start.Mode = spec.ModeQuick
+	}
+	start.Steps = spec.StepsForMode(start.Mode)
+	if resolved.Overwrite && !req.DryRun {
+		start.Archived, err = spec.ArchiveSpec(st, cfg.Spec.Config.Directory, resolved.Name, now())
+		if err != nil {
+			return SpecStart{}, err
+		}
+	}
+	return start, nil
+}
+
+// Apply records the spec's name and mode in the new workflow's data.
+func (s SpecStart) Apply(wf interface{ SetData(string, any) }) {
+	wf.SetData("name", s.Name)
+	if s.Mode != "" {
+		wf.SetData("mode", s.Mode)
+	}
+}
+
+// SpecSteps returns the step configs matching the mode the spec workflow
+// persisted at statePath was started in, so goto and status follow a quick
+// spec's shorter step list.
+func SpecSteps(statePath string) []workflow.StepConfig {
+	probe := workflow.New(spec.Steps(), statePath, workflow.Config{DryRun: true}, nil, nil)
+	mode, _ := probe.GetData("mode")
+	m, _ := mode.(string)
+	return spec.StepsForMode(m)
+}
+
+// RequireSpec checks that the spec a plan workflow is written from exists,
+// suggesting close spec names when it does not.
+func RequireSpec(st store.Store, cfg config.Config, name string) error {
+	rel := spec.SpecFilePath(cfg.Spec.Config.Directory, name)
+	if st.Exists(rel) {
+		return nil
+	}
+	path := filepath.Join(st.Root(), rel)
+	if hint := suggest.DidYouMean(suggest.Closest(name, spec.SpecNames(st, cfg.Spec.Config.Directory))); hint != "" {
+		return fmt.Errorf("spec file not found at %s — %s", path, hint)
+	}
+	return fmt.Errorf("spec file not found at %s — run 'spec new' first or check the name", path)
+}
+
+// RequirePlan checks that the plan an implement workflow runs against
+// exists, suggesting close plan names when it does not.
+func RequirePlan(st store.Store, cfg config.Config, name string) error {
+	rel := implement.PlanFilePath(cfg.Plan.Config.Directory, name)
+	if st.Exists(rel) {
+		return nil
+	}
+	path := filepath.Join(st.Root(), rel)
+	if hint := suggest.DidYouMean(suggest.Closest(name, planNames(st, cfg.Plan.Config.Directory))); hint != "" {
+		return fmt.Errorf("plan file not found at %s — %s", path, hint)
+	}
+	return fmt.Errorf("plan file not found at %s — run 'plan new' first or check the name", path)
+}
+
+// planNames lists the plans under the plan directory that contain a
+// plan.md, for "did you mean" suggestions.
+func planNames(st store.Store, planDir string) []string {
+	entries, err := st.List(planDir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir && st.Exists(implement.PlanFilePath(planDir, e.Name)) {
+			names = append(names, e.Name)
+		}
+	}
+	return names
+}
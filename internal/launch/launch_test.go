@@ -0,0 +1,98 @@
+package launch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/jumppad-labs/spektacular/internal/steps/spec"
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/jumppad-labs/spektacular/internal/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+type discard struct{}
+
+func (discard) WriteResult(any) error { return nil }
+
+func fixedNow() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+func TestWorkflowConfig_CarriesConfigDrivenFields(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.Spec.AppendQA = true
+
+	wfCfg := WorkflowConfig(cfg, true)
+	require.Equal(t, cfg.Command, wfCfg.Command)
+	require.True(t, wfCfg.DryRun)
+	require.Equal(t, cfg.Spec.Config.Directory, wfCfg.SpecDir)
+	require.Equal(t, cfg.Plan.Config.Directory, wfCfg.PlanDir)
+	require.True(t, wfCfg.SpecAppendQA)
+}
+
+func TestSpec_OverwriteArchivesUnlessDryRun(t *testing.T) {
+	cfg := config.NewDefault()
+	st := store.NewFileStore(t.TempDir(), "project")
+	require.NoError(t, st.Write(spec.SpecFilePath(cfg.Spec.Config.Directory, "ext-1-billing"), []byte("old")))
+	req := SpecRequest{Name: "billing", ID: "EXT-1", OnExists: spec.OnExistsOverwrite, Now: fixedNow}
+
+	dry := req
+	dry.DryRun = true
+	started, err := Spec(st, cfg, dry)
+	require.NoError(t, err)
+	require.Equal(t, "ext-1-billing", started.Name)
+	require.Empty(t, started.Archived)
+
+	started, err = Spec(st, cfg, req)
+	require.NoError(t, err)
+	require.Equal(t, cfg.Spec.Config.Directory+"/archive/ext-1-billing-20260102030405.md", started.Archived)
+	require.True(t, st.Exists(started.Archived))
+
+	_, err = Spec(st, cfg, SpecRequest{Name: "billing", ID: "EXT-1", Now: fixedNow})
+	require.ErrorIs(t, err, spec.ErrSpecExists)
+}
+
+func TestSpec_QuickModeRoundTripsThroughState(t *testing.T) {
+	cfg := config.NewDefault()
+	dir := t.TempDir()
+	st := store.NewFileStore(dir, "project")
+	statePath := filepath.Join(dir, "state.json")
+
+	started, err := Spec(st, cfg, SpecRequest{Name: "billing", Quick: true, Now: fixedNow})
+	require.NoError(t, err)
+	require.Equal(t, spec.ModeQuick, started.Mode)
+
+	wf := workflow.New(started.Steps, statePath, WorkflowConfig(cfg, false), st, discard{})
+	started.Apply(wf)
+	require.NoError(t, wf.Next(t.Context()))
+
+	names := workflow.New(SpecSteps(statePath), "", workflow.Config{}, nil, nil).StepNames()
+	require.Equal(t, []string{"new", "quick", "finished"}, names)
+	require.Len(t, SpecSteps(filepath.Join(dir, "missing.json")), len(spec.Steps()))
+}
+
+func TestRequireSpec_SuggestsCloseMatch(t *testing.T) {
+	cfg := config.NewDefault()
+	st := store.NewFileStore(t.TempDir(), "project")
+	require.NoError(t, st.Write(spec.SpecFilePath(cfg.Spec.Config.Directory, "my-feature"), []byte("spec")))
+
+	require.NoError(t, RequireSpec(st, cfg, "my-feature"))
+	require.ErrorContains(t, RequireSpec(st, cfg, "my-featrue"), "did you mean my-feature?")
+	require.ErrorContains(t, RequireSpec(st, cfg, "billing"), "run 'spec new' first")
+}
+
+func TestRequirePlan_SuggestsSymlinkedPlans(t *testing.T) {
+	cfg := config.NewDefault()
+	dir := t.TempDir()
+	st := store.NewFileStore(dir, "project")
+	require.NoError(t, st.Write(cfg.Plan.Config.Directory+"/local-feature/plan.md", []byte("plan")))
+	shared := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "plan.md"), []byte("plan"), 0o644))
+	require.NoError(t, os.Symlink(shared, filepath.Join(dir, cfg.Plan.Config.Directory, "shared-feature")))
+
+	require.ElementsMatch(t, []string{"local-feature", "shared-feature"}, planNames(st, cfg.Plan.Config.Directory))
+	require.NoError(t, RequirePlan(st, cfg, "shared-feature"))
+	require.ErrorContains(t, RequirePlan(st, cfg, "shared-featrue"), "did you mean shared-feature?")
+	require.ErrorContains(t, RequirePlan(st, cfg, "billing"), "run 'plan new' first")
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+)
+
+// answerPreviewBytes caps how much of a filed answer the transcript shows.
+const answerPreviewBytes = 200
+
+// fileAnswer moves an answer larger than answer_files.threshold_bytes out of
+// the resume prompt: it is written under config.AnswersDir in cwd and the
+// agent is sent a short message pointing at the file instead. onText, when
+// set, shows a preview plus the file reference. Answers at or under the
+// threshold, or with answer_files disabled, are returned unchanged.
+func fileAnswer(cfg config.Config, cwd, answer string, onText func(string)) (string, error) {
+	threshold := cfg.AnswerFiles.ThresholdBytes
+	if threshold <= 0 || len(answer) <= threshold {
+		return answer, nil
+	}
+
+	dir := filepath.Join(cwd, config.AnswersDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating answers directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, "answer-*.md")
+	if err != nil {
+		return "", fmt.Errorf("writing answer file: %w", err)
+	}
+	_, werr := f.WriteString(answer)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return "", fmt.Errorf("writing answer file: %w", werr)
+	}
+
+	rel := filepath.ToSlash(filepath.Join(config.AnswersDir, filepath.Base(f.Name())))
+	if onText != nil {
+		onText(fmt.Sprintf("%s\n[full answer (%s) saved to %s]\n", answerPreview(answer), FormatBytes(len(answer)), rel))
+	}
+	return answerFileMessage(rel), nil
+}
+
+// answerFileMessage is sent to the agent in place of a filed answer.
+func answerFileMessage(path string) string {
+	return fmt.Sprintf("My full answer is in %s; read it with your Read tool before continuing.", path)
+}
+
+// answerPreview returns the start of answer, cut on a rune boundary.
+func answerPreview(answer string) string {
+	answer = strings.TrimSpace(answer)
+	if len(answer) <= answerPreviewBytes {
+		return answer
+	}
+	cut := answer[:answerPreviewBytes]
+	for len(cut) > 0 && !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileAnswer_InlineAtOrUnderThreshold(t *testing.T) {
+	cwd := t.TempDir()
+	cfg := config.NewDefault()
+
+	got, err := fileAnswer(cfg, cwd, strings.Repeat("x", 10_000), nil)
+	require.NoError(t, err)
+	require.Len(t, got, 10_000, "answer_files is off by default")
+
+	cfg.AnswerFiles.ThresholdBytes = 100
+	got, err = fileAnswer(cfg, cwd, strings.Repeat("x", 100), nil)
+	require.NoError(t, err)
+	require.Len(t, got, 100)
+	require.NoDirExists(t, filepath.Join(cwd, config.AnswersDir))
+}
+
+func TestFileAnswer_WritesFileAndReference(t *testing.T) {
+	cwd := t.TempDir()
+	cfg := config.NewDefault()
+	cfg.AnswerFiles.ThresholdBytes = 100
+	answer := "Requirements from the design doc.\n\n" + strings.Repeat("detail ", 100)
+
+	var shown []string
+	got, err := fileAnswer(cfg, cwd, answer, func(s string) { shown = append(shown, s) })
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(cwd, config.AnswersDir, "answer-*.md"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	stored, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Equal(t, answer, string(stored))
+
+	rel := config.AnswersDir + "/" + filepath.Base(files[0])
+	require.Equal(t, "My full answer is in "+rel+"; read it with your Read tool before continuing.", got)
+	require.Len(t, shown, 1)
+	require.True(t, strings.HasPrefix(shown[0], "Requirements from the design doc."))
+	require.Contains(t, shown[0], "…\n[full answer (735B) saved to "+rel+"]")
+}
+
+func TestRunSteps_LongAnswerSentAsFileReference(t *testing.T) {
+	cwd := t.TempDir()
+	cfg := config.NewDefault()
+	cfg.AnswerFiles.ThresholdBytes = 16
+	r := &scriptedRunner{turns: [][]Event{questionTurn("sess-1"), {resultEvent("sess-1")}}}
+	steps := []Step{{Name: "plan", Prompts: Prompts{User: "write the plan"}}}
+	answer := func([]Question) string { return "postgres, with read replicas in two regions" }
+	require.NoError(t, RunSteps(t.Context(), r, steps, cfg, cwd, nil, answer))
+
+	require.Len(t, r.calls, 2)
+	require.Contains(t, r.calls[1].Prompts.User, "My full answer is in "+config.AnswersDir+"/answer-")
+	require.NotContains(t, r.calls[1].Prompts.User, "read replicas")
+}
@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+)
+
+// MaxAttachmentBytes caps how much of a single attached file is inlined into
+// the prompt. Larger files are truncated with a visible marker.
+const MaxAttachmentBytes = 64 * 1024
+
+// MaxAttachmentTotalBytes caps the combined attachment content inlined into
+// one prompt. Attachments past the budget are truncated or omitted.
+const MaxAttachmentTotalBytes = 256 * 1024
+
+// binarySniffBytes is how much of a file is checked for NUL bytes when
+// deciding whether it looks binary.
+const binarySniffBytes = 8000
+
+// Attachment is a file whose content is appended to the user prompt when a
+// step starts. Files are read fresh at that point, so an attachment always
+// reflects what is on disk rather than what existed when the step was built.
+type Attachment struct {
+	Path     string // absolute, or relative to RunOptions.CWD
+	Label    string // section heading; defaults to the file's base name
+	Required bool   // a missing required file fails the step before the agent starts
+}
+
+// AttachmentLimits bounds how much attachment content is inlined.
+type AttachmentLimits struct {
+	PerFile int // bytes per attachment
+	Total   int // bytes across all attachments in one prompt
+}
+
+// LimitsFromConfig returns the attachment limits configured in cfg, falling
+// back to MaxAttachmentBytes and MaxAttachmentTotalBytes for unset values.
+func LimitsFromConfig(cfg config.Config) AttachmentLimits {
+	limits := AttachmentLimits{PerFile: MaxAttachmentBytes, Total: MaxAttachmentTotalBytes}
+	if cfg.Attachments.MaxFileBytes > 0 {
+		limits.PerFile = cfg.Attachments.MaxFileBytes
+	}
+	if cfg.Attachments.MaxTotalBytes > 0 {
+		limits.Total = cfg.Attachments.MaxTotalBytes
+	}
+	return limits
+}
+
+// PrepareOptions resolves opts.Attachments into opts.Prompts.User and returns
+// the options with Attachments cleared, ready to hand to a Runner. Runners
+// never see attachments themselves, so prompt assembly lives in one place.
+// The returned warnings describe attachments that were truncated or skipped,
+// so the caller can surface them before the agent starts.
+func PrepareOptions(opts RunOptions) (RunOptions, []string, error) {
+	if len(opts.Attachments) == 0 {
+		return opts, nil, nil
+	}
+	user, warnings, err := AssemblePrompt(opts.Prompts.User, opts.CWD, opts.Attachments, LimitsFromConfig(opts.Config))
+	if err != nil {
+		return RunOptions{}, nil, err
+	}
+	opts.Prompts.User = user
+	opts.Attachments = nil
+	return opts, warnings, nil
+}
+
+// AssemblePrompt appends each attachment to prompt as a labelled, fenced
+// section. Optional attachments that do not exist are skipped; a missing
+// required attachment is an error naming the file. Files that look binary are
+// skipped, and content beyond limits is truncated with a visible marker; each
+// such case adds a warning.
+func AssemblePrompt(prompt, cwd string, attachments []Attachment, limits AttachmentLimits) (string, []string, error) {
+	var b strings.Builder
+	var warnings []string
+	b.WriteString(prompt)
+	used := 0
+	for _, a := range attachments {
+		path := a.Path
+		if !filepath.IsAbs(path) && cwd != "" {
+			path = filepath.Join(cwd, path)
+		}
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			if a.Required {
+				return "", nil, fmt.Errorf("required attachment %q not found at %s", attachmentLabel(a), path)
+			}
+			continue
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("reading attachment %q: %w", attachmentLabel(a), err)
+		}
+		if looksBinary(content) {
+			warnings = append(warnings, fmt.Sprintf("skipped attachment %s: it looks like a binary file", a.Path))
+			continue
+		}
+
+		budget := min(limits.PerFile, limits.Total-used)
+		if budget <= 0 {
+			fmt.Fprintf(&b, "\n\n---\n\n# %s\n\n[omitted: the %d-byte attachment budget is used up, read %s directly]", attachmentLabel(a), limits.Total, a.Path)
+			warnings = append(warnings, fmt.Sprintf("omitted attachment %s: the %d-byte attachment budget is used up", a.Path, limits.Total))
+			continue
+		}
+		shown := writeAttachment(&b, a, content, budget)
+		used += shown
+		if shown < len(content) {
+			warnings = append(warnings, fmt.Sprintf("truncated attachment %s to %d of %d bytes", a.Path, shown, len(content)))
+		}
+	}
+	return b.String(), warnings, nil
+}
+
+// writeAttachment renders one attachment section, inlining at most limit
+// bytes, and returns how many bytes it inlined. The fence is lengthened past
+// any backtick run in the content so embedded code blocks stay intact.
+func writeAttachment(b *strings.Builder, a Attachment, content []byte, limit int) int {
+	total := len(content)
+	truncated := total > limit
+	if truncated {
+		content = content[:runeBoundary(content, limit)]
+	}
+	text := string(content)
+	fence := strings.Repeat("`", max(3, longestRun(text, '`')+1))
+
+	fmt.Fprintf(b, "\n\n---\n\n# %s\n\nSource: %s\n\n%s\n%s", attachmentLabel(a), a.Path, fence, text)
+	if !strings.HasSuffix(text, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(fence)
+	if truncated {
+		fmt.Fprintf(b, "\n\n[truncated: showing %d of %d bytes, read %s for the rest]", len(content), total, a.Path)
+	}
+	return len(content)
+}
+
+// runeBoundary returns the largest n <= limit that does not split a UTF-8
+// sequence in content.
+func runeBoundary(content []byte, limit int) int {
+	n := limit
+	for n > 0 && n < len(content) && !utf8.RuneStart(content[n]) {
+		n--
+	}
+	return n
+}
+
+// looksBinary reports whether content has a NUL byte near the start or is not
+// valid UTF-8 — either way it would be noise in a prompt.
+func looksBinary(content []byte) bool {
+	if bytes.IndexByte(content[:min(len(content), binarySniffBytes)], 0) >= 0 {
+		return true
+	}
+	return !utf8.Valid(content)
+}
+
+func attachmentLabel(a Attachment) string {
+	if a.Label != "" {
+		return a.Label
+	}
+	return filepath.Base(a.Path)
+}
+
+// longestRun returns the length of the longest run of r in s.
+func longestRun(s string, r rune) int {
+	longest, current := 0, 0
+	for _, c := range s {
+		if c == r {
+			current++
+			longest = max(longest, current)
+			continue
+		}
+		current = 0
+	}
+	return longest
+}
@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultLimits = LimitsFromConfig(config.NewDefault())
+
+func TestAssemblePrompt_AppendsLabelledSections(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.md"), []byte("# Spec\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conventions.md"), []byte("use tabs"), 0644))
+
+	prompt, _, err := AssemblePrompt("do the thing", dir, []Attachment{
+		{Path: "spec.md", Label: "Specification", Required: true},
+		{Path: "conventions.md"},
+	}, defaultLimits)
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(prompt, "do the thing"))
+	require.Contains(t, prompt, "# Specification\n\nSource: spec.md\n\n```\n# Spec\n```")
+	require.Contains(t, prompt, "# conventions.md\n\nSource: conventions.md\n\n```\nuse tabs\n```")
+	require.Less(t, strings.Index(prompt, "Specification"), strings.Index(prompt, "conventions.md"))
+}
+
+func TestAssemblePrompt_MissingOptionalIsSkipped(t *testing.T) {
+	prompt, _, err := AssemblePrompt("base", t.TempDir(), []Attachment{{Path: "research.md"}}, defaultLimits)
+	require.NoError(t, err)
+	require.Equal(t, "base", prompt)
+}
+
+func TestAssemblePrompt_MissingRequiredErrors(t *testing.T) {
+	_, _, err := AssemblePrompt("base", t.TempDir(), []Attachment{{Path: "plan.md", Label: "Plan", Required: true}}, defaultLimits)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `required attachment "Plan" not found`)
+}
+
+func TestAssemblePrompt_TruncatesOversizedFiles(t *testing.T) {
+	dir := t.TempDir()
+	big := strings.Repeat("x", MaxAttachmentBytes+100)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "research.md"), []byte(big), 0644))
+
+	prompt, _, err := AssemblePrompt("", dir, []Attachment{{Path: "research.md"}}, defaultLimits)
+	require.NoError(t, err)
+	require.Equal(t, MaxAttachmentBytes, strings.Count(prompt, "x"))
+	require.Contains(t, prompt, "[truncated: showing 65536 of 65636 bytes, read research.md for the rest]")
+}
+
+func TestAssemblePrompt_FenceOutgrowsEmbeddedBackticks(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.md"), []byte("```go\nx := 1\n```\n"), 0644))
+
+	prompt, _, err := AssemblePrompt("", dir, []Attachment{{Path: "plan.md"}}, defaultLimits)
+	require.NoError(t, err)
+	require.Contains(t, prompt, "````\n```go\nx := 1\n```\n````")
+}
+
+func TestRunSteps_AttachmentsOnlyOnFirstTurn(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.md"), []byte("spec body"), 0644))
+
+	// The first turn stops on a question without a result event, so the
+	// step resumes the session with the answer as a second turn.
+	r := &scriptedRunner{turns: [][]Event{
+		{
+			{Type: "system", Data: map[string]any{"session_id": "s1"}},
+			assistantText(`<!--QUESTION:{"questions":[{"question":"Which?","header":"H"}]}-->`),
+		},
+		{assistantText("ok <!-- FINISHED -->"), resultEvent("s1")},
+	}}
+	steps := []Step{{
+		Prompts:     Prompts{User: "plan it"},
+		Attachments: []Attachment{{Path: "spec.md", Required: true}},
+	}}
+
+	err := RunSteps(t.Context(), r, steps, config.NewDefault(), dir, nil, func([]Question) string { return "A" })
+	require.NoError(t, err)
+
+	require.Len(t, r.calls, 2)
+	require.Contains(t, r.calls[0].Prompts.User, "spec body")
+	require.Nil(t, r.calls[0].Attachments)
+	require.Equal(t, "A", r.calls[1].Prompts.User)
+}
+
+func TestRunSteps_MissingRequiredAttachmentFailsBeforeAgentStarts(t *testing.T) {
+	r := &scriptedRunner{}
+	steps := []Step{{
+		Prompts:     Prompts{User: "plan it"},
+		Attachments: []Attachment{{Path: "spec.md", Required: true}},
+	}}
+
+	err := RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, nil)
+	require.Error(t, err)
+	require.Empty(t, r.calls)
+}
+
+func TestAssemblePrompt_TotalBudgetTruncatesThenOmits(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat("q", 60)), 0644))
+	}
+
+	prompt, warnings, err := AssemblePrompt("", dir, []Attachment{{Path: "a.md"}, {Path: "b.md"}, {Path: "c.md"}},
+		AttachmentLimits{PerFile: 100, Total: 100})
+	require.NoError(t, err)
+
+	require.Equal(t, 100, strings.Count(prompt, "q"))
+	require.Contains(t, prompt, "[truncated: showing 40 of 60 bytes, read b.md for the rest]")
+	require.Contains(t, prompt, "# c.md\n\n[omitted: the 100-byte attachment budget is used up, read c.md directly]")
+	require.Equal(t, []string{
+		"truncated attachment b.md to 40 of 60 bytes",
+		"omitted attachment c.md: the 100-byte attachment budget is used up",
+	}, warnings)
+}
+
+func TestAssemblePrompt_SkipsBinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte("\x89PNG\r\n\x1a\n\x00\x00"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "latin1.txt"), []byte("caf\xe9"), 0644))
+
+	prompt, warnings, err := AssemblePrompt("base", dir, []Attachment{{Path: "logo.png"}, {Path: "latin1.txt"}}, defaultLimits)
+	require.NoError(t, err)
+	require.Equal(t, "base", prompt)
+	require.Equal(t, []string{
+		"skipped attachment logo.png: it looks like a binary file",
+		"skipped attachment latin1.txt: it looks like a binary file",
+	}, warnings)
+}
+
+func TestAssemblePrompt_TruncationKeepsRunesWhole(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("ab€cd"), 0644))
+
+	prompt, _, err := AssemblePrompt("", dir, []Attachment{{Path: "notes.md"}}, AttachmentLimits{PerFile: 4, Total: 100})
+	require.NoError(t, err)
+	require.Contains(t, prompt, "```\nab\n```")
+	require.Contains(t, prompt, "[truncated: showing 2 of 7 bytes")
+}
+
+func TestLimitsFromConfig(t *testing.T) {
+	require.Equal(t, AttachmentLimits{PerFile: MaxAttachmentBytes, Total: MaxAttachmentTotalBytes}, LimitsFromConfig(config.NewDefault()))
+
+	cfg := config.NewDefault()
+	cfg.Attachments.MaxFileBytes = 10
+	cfg.Attachments.MaxTotalBytes = 20
+	require.Equal(t, AttachmentLimits{PerFile: 10, Total: 20}, LimitsFromConfig(cfg))
+}
+
+func TestRunSteps_ReportsAttachmentWarningsBeforeAgentStarts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "research.md"), []byte(strings.Repeat("z", 50)), 0644))
+	cfg := config.NewDefault()
+	cfg.Attachments.MaxFileBytes = 10
+
+	r := &scriptedRunner{turns: [][]Event{{resultEvent("s")}}}
+	steps := []Step{{Prompts: Prompts{User: "plan"}, Attachments: []Attachment{{Path: "research.md"}}}}
+	var texts []string
+	require.NoError(t, RunSteps(t.Context(), r, steps, cfg, dir, func(s string) { texts = append(texts, s) }, nil))
+
+	require.Equal(t, []string{"warning: truncated attachment research.md to 10 of 50 bytes\n"}, texts)
+}
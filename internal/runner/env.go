@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"sort"
+	"strings"
+)
+
+// AgentEnviron builds the environment for an agent process from base
+// (typically os.Environ()): variables matching Config.AgentEnv.Remove are
+// dropped, then Config.AgentEnv.Set and opts.Env are applied, with opts.Env
+// winning. Variables already in base keep their position; new ones are
+// appended in name order. Backends assign the result to exec.Cmd.Env.
+func AgentEnviron(base []string, opts RunOptions) []string {
+	set := map[string]string{}
+	for k, v := range opts.Config.AgentEnv.Set {
+		set[k] = v
+	}
+	for k, v := range opts.Env {
+		set[k] = v
+	}
+
+	env := make([]string, 0, len(base)+len(set))
+	applied := map[string]bool{}
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if v, ok := set[name]; ok {
+			if !applied[name] {
+				env = append(env, name+"="+v)
+				applied[name] = true
+			}
+			continue
+		}
+		if envRemoved(name, opts.Config.AgentEnv.Remove) {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	var added []string
+	for name := range set {
+		if !applied[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		env = append(env, name+"="+set[name])
+	}
+	return env
+}
+
+// envRemoved reports whether name matches one of the remove patterns.
+func envRemoved(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			continue
+		}
+		if name == p {
+			return true
+		}
+	}
+	return false
+}
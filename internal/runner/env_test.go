@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentEnviron_SetRemoveAndOverride(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.AgentEnv.Set = map[string]string{"HTTPS_PROXY": "http://proxy:3128", "HOME": "/agent"}
+	cfg.AgentEnv.Remove = []string{"AWS_*", "GITHUB_TOKEN"}
+	opts := RunOptions{Config: cfg, Env: map[string]string{"HOME": "/step", "STEP": "overview"}}
+
+	base := []string{"PATH=/bin", "HOME=/root", "AWS_ACCESS_KEY_ID=x", "AWS_SECRET_ACCESS_KEY=y", "GITHUB_TOKEN=z", "GITHUB_USER=u"}
+	require.Equal(t, []string{
+		"PATH=/bin",
+		"HOME=/step",
+		"GITHUB_USER=u",
+		"HTTPS_PROXY=http://proxy:3128",
+		"STEP=overview",
+	}, AgentEnviron(base, opts))
+}
+
+func TestAgentEnviron_NoConfigKeepsBase(t *testing.T) {
+	base := []string{"PATH=/bin", "HOME=/root"}
+	require.Equal(t, base, AgentEnviron(base, RunOptions{Config: config.NewDefault()}))
+}
+
+func TestAgentEnviron_ChildProcessSeesEnvironment(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	t.Setenv("SPEK_SECRET", "leak")
+	cfg := config.NewDefault()
+	cfg.AgentEnv.Set = map[string]string{"SPEK_PROXY": "http://proxy:3128"}
+	cfg.AgentEnv.Remove = []string{"SPEK_SECRET"}
+
+	cmd := exec.Command(sh, "-c", `echo "proxy=$SPEK_PROXY secret=$SPEK_SECRET"`)
+	cmd.Env = AgentEnviron([]string{"SPEK_SECRET=leak"}, RunOptions{Config: cfg})
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	require.Equal(t, "proxy=http://proxy:3128 secret=", strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+)
+
+// DefaultLargeWriteBytes is the Write tool content size above which a write
+// is flagged when large_writes.threshold_bytes is not set.
+const DefaultLargeWriteBytes = 512 * 1024
+
+// LargeWrite is a Write tool call whose content exceeded the threshold.
+type LargeWrite struct {
+	Path  string
+	Bytes int
+}
+
+// Warning describes the write for display.
+func (w LargeWrite) Warning() string {
+	return fmt.Sprintf("agent wrote %s to %s — review this", FormatBytes(w.Bytes), w.Path)
+}
+
+// LargeWriteThreshold returns the configured large-write threshold, falling
+// back to DefaultLargeWriteBytes.
+func LargeWriteThreshold(cfg config.Config) int {
+	if cfg.LargeWrites.ThresholdBytes > 0 {
+		return cfg.LargeWrites.ThresholdBytes
+	}
+	return DefaultLargeWriteBytes
+}
+
+// DetectLargeWrites returns the Write tool calls in e whose content is
+// larger than threshold bytes.
+func DetectLargeWrites(e Event, threshold int) []LargeWrite {
+	var writes []LargeWrite
+	for _, tool := range e.ToolUses() {
+		if tool["name"] != "Write" {
+			continue
+		}
+		input, _ := tool["input"].(map[string]any)
+		content, _ := input["content"].(string)
+		if len(content) <= threshold {
+			continue
+		}
+		path, _ := input["file_path"].(string)
+		if path == "" {
+			path = "(unknown file)"
+		}
+		writes = append(writes, LargeWrite{Path: path, Bytes: len(content)})
+	}
+	return writes
+}
+
+// FormatBytes renders n as a short human-readable size ("1.2MB", "640KB").
+func FormatBytes(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.0fKB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// confirmWritesPrompt asks the agent to confirm large writes were
+// intentional before the step moves on.
+func confirmWritesPrompt(p *Protocol, writes []LargeWrite) string {
+	var b strings.Builder
+	b.WriteString("You wrote unusually large files:\n\n")
+	for _, w := range writes {
+		fmt.Fprintf(&b, "- %s (%s)\n", w.Path, FormatBytes(w.Bytes))
+	}
+	b.WriteString("\nConfirm each was intentional. If one was not, remove or regenerate it properly. ")
+	b.WriteString("If the step is complete, emit " + p.FinishedMarker() + ".")
+	return b.String()
+}
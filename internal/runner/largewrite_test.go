@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// writeEvent builds an assistant event carrying a single Write tool call.
+func writeEvent(path string, size int) Event {
+	return Event{Type: "assistant", Data: map[string]any{
+		"message": map[string]any{"content": []any{map[string]any{
+			"type":  "tool_use",
+			"name":  "Write",
+			"input": map[string]any{"file_path": path, "content": strings.Repeat("x", size)},
+		}}},
+	}}
+}
+
+func TestDetectLargeWrites(t *testing.T) {
+	require.Equal(t, []LargeWrite{{Path: "web/bundle.js", Bytes: 2048}}, DetectLargeWrites(writeEvent("web/bundle.js", 2048), 1024))
+	require.Empty(t, DetectLargeWrites(writeEvent("main.go", 1024), 1024))
+	require.Empty(t, DetectLargeWrites(assistantText("no tools"), 0))
+
+	edit := Event{Type: "assistant", Data: map[string]any{
+		"message": map[string]any{"content": []any{map[string]any{
+			"type": "tool_use", "name": "Edit",
+			"input": map[string]any{"file_path": "a.go", "new_string": strings.Repeat("x", 4096)},
+		}}},
+	}}
+	require.Empty(t, DetectLargeWrites(edit, 1024), "only Write calls are checked")
+}
+
+func TestLargeWrite_Warning(t *testing.T) {
+	w := LargeWrite{Path: "web/bundle.js", Bytes: 1258291}
+	require.Equal(t, "agent wrote 1.2MB to web/bundle.js — review this", w.Warning())
+	require.Equal(t, "640KB", FormatBytes(640*1024))
+	require.Equal(t, "12B", FormatBytes(12))
+}
+
+func TestLargeWriteThreshold(t *testing.T) {
+	cfg := config.NewDefault()
+	require.Equal(t, DefaultLargeWriteBytes, LargeWriteThreshold(cfg))
+	cfg.LargeWrites.ThresholdBytes = 10
+	require.Equal(t, 10, LargeWriteThreshold(cfg))
+}
+
+func TestRunSteps_LargeWriteWarns(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.LargeWrites.ThresholdBytes = 100
+
+	r := &scriptedRunner{turns: [][]Event{{writeEvent("web/bundle.js", 200), resultEvent("sess-1")}}}
+	var shown []string
+	onText := func(s string) { shown = append(shown, s) }
+	require.NoError(t, RunSteps(t.Context(), r, []Step{{Prompts: Prompts{User: "implement"}}}, cfg, t.TempDir(), onText, nil))
+
+	require.Equal(t, []string{"warning: agent wrote 200B to web/bundle.js — review this\n"}, shown)
+	require.Len(t, r.calls, 1, "without large_writes.confirm no follow-up turn is sent")
+}
+
+func TestRunSteps_LargeWriteConfirmTurn(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.LargeWrites.ThresholdBytes = 100
+	cfg.LargeWrites.Confirm = true
+
+	r := &scriptedRunner{turns: [][]Event{
+		{writeEvent("web/bundle.js", 200), resultEvent("sess-1")},
+		{writeEvent("web/bundle.js", 200), assistantText("Intentional."), resultEvent("sess-1")},
+	}}
+	require.NoError(t, RunSteps(t.Context(), r, []Step{{Prompts: Prompts{User: "implement"}}}, cfg, t.TempDir(), nil, nil))
+
+	require.Len(t, r.calls, 2, "a rewrite of a confirmed file does not ask again")
+	require.Equal(t, "sess-1", r.calls[1].SessionID)
+	require.Contains(t, r.calls[1].Prompts.User, "- web/bundle.js (200B)")
+	require.Contains(t, r.calls[1].Prompts.User, "Confirm each was intentional")
+}
+
+func TestRunSteps_LargeWriteConfirmSkippedWithoutSession(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.LargeWrites.ThresholdBytes = 100
+	cfg.LargeWrites.Confirm = true
+
+	r := &scriptedRunner{turns: [][]Event{{writeEvent("web/bundle.js", 200), resultEvent("")}}}
+	require.NoError(t, RunSteps(t.Context(), r, []Step{{Prompts: Prompts{User: "implement"}}}, cfg, t.TempDir(), nil, nil))
+	require.Len(t, r.calls, 1)
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+)
+
+// DefaultMaxRepeats is how many times the same question or turn text may
+// appear within a step before the agent is considered to be looping.
+const DefaultMaxRepeats = 3
+
+// maxLoopExcerpt caps how much of the repeated content the error quotes.
+const maxLoopExcerpt = 500
+
+// ErrAgentLooping is returned when an agent keeps repeating itself within a
+// step.
+var ErrAgentLooping = errors.New("agent appears to be looping")
+
+// MaxRepeats returns the configured loop threshold, falling back to
+// DefaultMaxRepeats.
+func MaxRepeats(cfg config.Config) int {
+	if cfg.LoopDetection.MaxRepeats > 0 {
+		return cfg.LoopDetection.MaxRepeats
+	}
+	return DefaultMaxRepeats
+}
+
+// loopDetector counts fingerprints of the questions and turn text an agent
+// produces within one step.
+type loopDetector struct {
+	max       int
+	questions map[string]int
+	texts     map[string]int
+}
+
+func newLoopDetector(max int) *loopDetector {
+	return &loopDetector{max: max, questions: map[string]int{}, texts: map[string]int{}}
+}
+
+// observe records one turn's text and questions and returns an
+// ErrAgentLooping error once either has been seen max times.
+func (d *loopDetector) observe(text string, questions []Question) error {
+	for _, q := range questions {
+		fp := fingerprint(q.Header + "\x00" + q.Question)
+		if fp == "\x00" {
+			continue
+		}
+		d.questions[fp]++
+		if d.questions[fp] >= d.max {
+			return loopError("the same question", d.questions[fp], q.Question)
+		}
+	}
+	if fp := fingerprint(text); fp != "" {
+		d.texts[fp]++
+		if d.texts[fp] >= d.max {
+			return loopError("the same response", d.texts[fp], text)
+		}
+	}
+	return nil
+}
+
+// fingerprint normalises case and whitespace so trivially different
+// repetitions still match.
+func fingerprint(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func loopError(what string, times int, content string) error {
+	content = strings.TrimSpace(content)
+	if len(content) > maxLoopExcerpt {
+		content = content[:runeBoundary([]byte(content), maxLoopExcerpt)] + "…"
+	}
+	return fmt.Errorf("%w: it produced %s %d times. Try rewording your answer or editing the spec before retrying. Repeated content:\n\n%s", ErrAgentLooping, what, times, content)
+}
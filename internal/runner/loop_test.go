@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSteps_StopsWhenAgentRepeatsQuestion(t *testing.T) {
+	turns := make([][]Event, 5)
+	for i := range turns {
+		turns[i] = questionTurn("sess-1")
+	}
+	r := &scriptedRunner{turns: turns}
+	answers := 0
+	answer := func([]Question) string { answers++; return "postgres" }
+
+	err := RunSteps(t.Context(), r, []Step{{Name: "plan", Prompts: Prompts{User: "write the plan"}}}, config.NewDefault(), t.TempDir(), nil, answer)
+	require.ErrorIs(t, err, ErrAgentLooping)
+	require.ErrorContains(t, err, `step "plan"`)
+	require.ErrorContains(t, err, "the same question 3 times")
+	require.ErrorContains(t, err, "Which database?")
+	require.Len(t, r.calls, 3)
+	require.Equal(t, 2, answers, "the user is not asked a third time")
+}
+
+func TestRunSteps_StopsWhenAgentRepeatsText(t *testing.T) {
+	turn := []Event{assistantText("I will now  write the overview."), resultEvent("sess-1")}
+	r := &scriptedRunner{turns: [][]Event{turn, turn, turn}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write it"}, RequiresFinished: true}}
+	cfg := config.NewDefault()
+	cfg.LoopDetection.MaxRepeats = 2
+
+	err := RunSteps(t.Context(), r, steps, cfg, t.TempDir(), nil, nil)
+	require.ErrorIs(t, err, ErrAgentLooping)
+	require.ErrorContains(t, err, "the same response 2 times")
+	require.Len(t, r.calls, 2)
+}
+
+func TestRunSteps_VariedQuestionsDoNotTripLoopDetection(t *testing.T) {
+	ask := func(q string) []Event {
+		return []Event{
+			{Type: "system", Data: map[string]any{"session_id": "sess-1"}},
+			assistantText(`<!--QUESTION:{"questions":[{"question":"` + q + `"}]}-->`),
+		}
+	}
+	r := &scriptedRunner{turns: [][]Event{ask("One?"), ask("Two?"), ask("Three?"), {resultEvent("sess-1")}}}
+	answer := func([]Question) string { return "ok" }
+	require.NoError(t, RunSteps(t.Context(), r, []Step{{Prompts: Prompts{User: "go"}}}, config.NewDefault(), t.TempDir(), nil, answer))
+	require.Len(t, r.calls, 4)
+}
+
+func TestLoopError_TruncatesContent(t *testing.T) {
+	err := loopError("the same response", 3, strings.Repeat("é", maxLoopExcerpt))
+	require.ErrorIs(t, err, ErrAgentLooping)
+	require.True(t, strings.HasSuffix(err.Error(), "…"))
+	require.Less(t, len(err.Error()), maxLoopExcerpt+300)
+}
@@ -0,0 +1,138 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxOptions caps how many options a choice question keeps; the rest are
+// dropped so a runaway list cannot swamp the prompt.
+const MaxOptions = 12
+
+// Option is one normalized choice-question option. Label is always set.
+// Value, when the agent gave one, is what an answer picking the option sends
+// back instead of the label.
+type Option struct {
+	Label       string
+	Description string
+	Value       string
+}
+
+// Answer returns the text sent to the agent when the option is picked.
+func (o Option) Answer() string {
+	if o.Value != "" {
+		return o.Value
+	}
+	return o.Label
+}
+
+// normalizeOptions turns an agent's raw options payload into Options.
+// Agents do not always send well-formed {label, description} objects, so a
+// bare string or number is taken as the label, non-string labels are
+// coerced with fmt.Sprint, and entries with no usable label (missing, empty
+// or a nested object) are dropped rather than shown as blank, selectable
+// rows. At most MaxOptions are kept. dropped counts every entry left out.
+func normalizeOptions(raw []any) (options []Option, dropped int) {
+	for _, entry := range raw {
+		var o Option
+		switch v := entry.(type) {
+		case map[string]any:
+			o = Option{
+				Label:       scalarText(v["label"]),
+				Description: scalarText(v["description"]),
+				Value:       scalarText(v["value"]),
+			}
+		default:
+			o = Option{Label: scalarText(v)}
+		}
+		if o.Label == "" || len(options) == MaxOptions {
+			dropped++
+			continue
+		}
+		options = append(options, o)
+	}
+	return options, dropped
+}
+
+// scalarText renders a JSON scalar as trimmed text. Objects, arrays and null
+// have no sensible text form and yield "".
+func scalarText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// optionHistory remembers, per question header, the order in which a choice
+// question's options were first presented during a step. Agents that re-ask
+// a question after clarification sometimes shuffle the same options, which
+// trips up users answering by number; stabilize puts them back.
+type optionHistory map[string][]Option
+
+// stabilize reorders q.Options to match the order they were first presented
+// under the same header, when the re-asked set is identical (compared on
+// label, description and value). Differing option sets are left as they are
+// and become the new reference order. It reports whether q was reordered, and
+// records that on q.Reordered. Answers are sent back as Option.Answer text,
+// not by position, so the reorder never changes what an answer means.
+func (h optionHistory) stabilize(q *Question) bool {
+	if q.Type != QuestionTypeChoice || q.Header == "" {
+		return false
+	}
+	prev, seen := h[q.Header]
+	if !seen || !sameOptionSet(prev, q.Options) || sameOptionOrder(prev, q.Options) {
+		h[q.Header] = q.Options
+		return false
+	}
+
+	byKey := make(map[string][]Option, len(q.Options))
+	for _, o := range q.Options {
+		k := optionKey(o)
+		byKey[k] = append(byKey[k], o)
+	}
+	reordered := make([]Option, 0, len(prev))
+	for _, o := range prev {
+		k := optionKey(o)
+		reordered = append(reordered, byKey[k][0])
+		byKey[k] = byKey[k][1:]
+	}
+	q.Options = reordered
+	q.Reordered = true
+	return true
+}
+
+// sameOptionSet reports whether a and b hold the same options, ignoring order.
+func sameOptionSet(a, b []Option) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, o := range a {
+		counts[optionKey(o)]++
+	}
+	for _, o := range b {
+		k := optionKey(o)
+		if counts[k] == 0 {
+			return false
+		}
+		counts[k]--
+	}
+	return true
+}
+
+func sameOptionOrder(a, b []Option) bool {
+	for i := range a {
+		if optionKey(a[i]) != optionKey(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// optionKey identifies an option by its label, description and value.
+func optionKey(o Option) string {
+	return o.Label + "\x00" + o.Description + "\x00" + o.Value
+}
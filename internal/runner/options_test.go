@@ -0,0 +1,153 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func choice(header string, labels ...string) Question {
+	opts := make([]Option, len(labels))
+	for i, l := range labels {
+		opts[i] = Option{Label: l, Description: "about " + l}
+	}
+	return Question{Question: "Pick one", Header: header, Type: QuestionTypeChoice, Options: opts}
+}
+
+func labels(q Question) []string {
+	out := make([]string, len(q.Options))
+	for i, o := range q.Options {
+		out[i] = o.Label
+	}
+	return out
+}
+
+func TestOptionHistory_RestoresOriginalOrder(t *testing.T) {
+	h := optionHistory{}
+	first := choice("Approach", "A", "B", "C")
+	require.False(t, h.stabilize(&first))
+
+	reask := choice("Approach", "C", "A", "B")
+	require.True(t, h.stabilize(&reask))
+	require.True(t, reask.Reordered)
+	require.Equal(t, []string{"A", "B", "C"}, labels(reask))
+}
+
+func TestOptionHistory_LeavesDifferentSetsAlone(t *testing.T) {
+	h := optionHistory{}
+	first := choice("Approach", "A", "B", "C")
+	h.stabilize(&first)
+
+	changed := choice("Approach", "C", "A", "D")
+	require.False(t, h.stabilize(&changed))
+	require.Equal(t, []string{"C", "A", "D"}, labels(changed))
+
+	// The changed set becomes the new reference order.
+	again := choice("Approach", "A", "D", "C")
+	require.True(t, h.stabilize(&again))
+	require.Equal(t, []string{"C", "A", "D"}, labels(again))
+}
+
+func TestOptionHistory_ComparesDescriptions(t *testing.T) {
+	h := optionHistory{}
+	first := choice("Approach", "A", "B")
+	h.stabilize(&first)
+
+	reask := choice("Approach", "B", "A")
+	reask.Options[0].Description = "a different B"
+	require.False(t, h.stabilize(&reask))
+	require.Equal(t, []string{"B", "A"}, labels(reask))
+}
+
+func TestOptionHistory_ComparesValues(t *testing.T) {
+	h := optionHistory{}
+	first := choice("Approach", "A", "B")
+	h.stabilize(&first)
+
+	reask := choice("Approach", "B", "A")
+	reask.Options[0].Value = "b-v2"
+	require.False(t, h.stabilize(&reask))
+	require.Equal(t, []string{"B", "A"}, labels(reask))
+}
+
+func TestOptionHistory_IgnoresOtherHeadersAndTextQuestions(t *testing.T) {
+	h := optionHistory{}
+	first := choice("Approach", "A", "B")
+	h.stabilize(&first)
+
+	other := choice("Database", "B", "A")
+	require.False(t, h.stabilize(&other))
+
+	text := Question{Question: "Why?", Header: "Approach", Type: QuestionTypeText}
+	require.False(t, h.stabilize(&text))
+}
+
+func TestSameOptionSet(t *testing.T) {
+	require.True(t, sameOptionSet(choice("h", "A", "B").Options, choice("h", "B", "A").Options))
+	require.False(t, sameOptionSet(choice("h", "A", "B").Options, choice("h", "A", "B", "C").Options))
+	require.False(t, sameOptionSet(choice("h", "A", "A").Options, choice("h", "A", "B").Options))
+}
+
+func TestRunSteps_StabilizesReaskedOptions(t *testing.T) {
+	ask := func(order string) Event {
+		return assistantText(`<!--QUESTION:{"questions":[{"question":"Which?","header":"Approach","type":"choice","options":` + order + `}]}-->`)
+	}
+	r := &scriptedRunner{turns: [][]Event{
+		{ask(`[{"label":"A"},{"label":"B"}]`)},
+		{ask(`[{"label":"B"},{"label":"A"}]`)},
+		{resultEvent("s")},
+	}}
+
+	var seen [][]string
+	onQuestion := func(qs []Question) string {
+		seen = append(seen, labels(qs[0]))
+		return "A"
+	}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "go"}}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, onQuestion))
+
+	require.Equal(t, [][]string{{"A", "B"}, {"A", "B"}}, seen)
+}
+
+func TestDetectQuestions_NormalizesMalformedOptions(t *testing.T) {
+	text := `<!--QUESTION:{"questions":[{"question":"Retries?","header":"Retry","type":"choice","options":[` +
+		`{"label":3,"description":"default"},` +
+		`{"label":{"text":"nested"}},` +
+		`{"description":"no label"},` +
+		`{"label":"  "},` +
+		`"Unlimited",` +
+		`{"label":"Off","value":"0"},` +
+		`null]}]}-->`
+	questions := detectQuestions(text)
+	require.Len(t, questions, 1)
+	q := questions[0]
+	require.Equal(t, QuestionTypeChoice, q.Type)
+	require.Equal(t, []Option{
+		{Label: "3", Description: "default"},
+		{Label: "Unlimited"},
+		{Label: "Off", Value: "0"},
+	}, q.Options)
+	require.Equal(t, 4, q.DroppedOptions)
+	require.Equal(t, "0", q.Options[2].Answer())
+	require.Equal(t, "Unlimited", q.Options[1].Answer())
+}
+
+func TestDetectQuestions_ChoiceWithNoUsableOptionsIsText(t *testing.T) {
+	questions := detectQuestions(`<!--QUESTION:{"questions":[{"question":"Name?","type":"choice","options":[{"description":"x"},{}]}]}-->`)
+	require.Len(t, questions, 1)
+	require.Equal(t, QuestionTypeText, questions[0].Type)
+	require.Empty(t, questions[0].Options)
+	require.Equal(t, 2, questions[0].DroppedOptions)
+}
+
+func TestNormalizeOptions_CapsLength(t *testing.T) {
+	raw := make([]any, MaxOptions+3)
+	for i := range raw {
+		raw[i] = float64(i + 1)
+	}
+	options, dropped := normalizeOptions(raw)
+	require.Len(t, options, MaxOptions)
+	require.Equal(t, "12", options[MaxOptions-1].Label)
+	require.Equal(t, 3, dropped)
+}
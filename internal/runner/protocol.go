@@ -0,0 +1,212 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+)
+
+// Protocol is the compiled set of markers an agent uses to ask questions,
+// signal that a step is finished and jump to another step. The default
+// comment style uses HTML comments (<!--QUESTION:{...}-->, <!-- FINISHED -->);
+// the fence style uses fenced blocks (```question, ```finished, ```goto) for
+// backends or models that mangle HTML comments.
+type Protocol struct {
+	style    string
+	question *regexp.Regexp // group 1 is the questions JSON payload
+	finished *regexp.Regexp
+	gotoStep *regexp.Regexp // group 1 is the target step name
+}
+
+// DefaultProtocol matches the comment-style markers.
+var DefaultProtocol = mustProtocol(config.QuestionStyleComment)
+
+// NewProtocol compiles the markers for a question style: "comment" (or
+// empty) or "fence".
+func NewProtocol(style string) (*Protocol, error) {
+	switch style {
+	case "", config.QuestionStyleComment:
+		return &Protocol{
+			style:    config.QuestionStyleComment,
+			question: regexp.MustCompile(`<!--QUESTION:([\s\S]*?)-->`),
+			finished: regexp.MustCompile(`<!--\s*FINISHED\s*-->`),
+			gotoStep: regexp.MustCompile(`<!--\s*GOTO:\s*([\w][\w\s-]*?)\s*-->`),
+		}, nil
+	case config.QuestionStyleFence:
+		return &Protocol{
+			style:    config.QuestionStyleFence,
+			question: regexp.MustCompile("```question[ \\t]*\\n([\\s\\S]*?)\\n[ \\t]*```"),
+			finished: regexp.MustCompile("```finished\\s*```"),
+			gotoStep: regexp.MustCompile("```goto[ \\t]*\\n\\s*([\\w][\\w\\s-]*?)\\s*```"),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported question style %q (use %q or %q)", style, config.QuestionStyleComment, config.QuestionStyleFence)
+}
+
+// ProtocolFromConfig returns the protocol selected by protocol.question_style.
+func ProtocolFromConfig(cfg config.Config) (*Protocol, error) {
+	return NewProtocol(cfg.Protocol.QuestionStyle)
+}
+
+func mustProtocol(style string) *Protocol {
+	p, err := NewProtocol(style)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Style returns the protocol's question style.
+func (p *Protocol) Style() string { return p.style }
+
+// FinishedMarker returns the marker text the agent emits to finish a step.
+func (p *Protocol) FinishedMarker() string {
+	if p.style == config.QuestionStyleFence {
+		return "```finished\n```"
+	}
+	return "<!-- FINISHED -->"
+}
+
+// Instructions returns the prompt paragraph telling the agent how to ask
+// questions and finish a step in this protocol's style. RunSteps passes it to
+// every step as Prompts.AppendSystem so the agent emits the markers the
+// protocol detects.
+func (p *Protocol) Instructions() string {
+	example := `{"questions":[{"question":"Which database should we use?","header":"Storage","type":"choice","options":[{"label":"PostgreSQL","description":"Relational"},{"label":"SQLite","description":"Embedded"}]}]}`
+	var ask string
+	if p.style == config.QuestionStyleFence {
+		ask = "```question\n" + example + "\n```"
+	} else {
+		ask = "<!--QUESTION:" + example + "-->"
+	}
+	return "To ask the user questions, emit a question block and stop to wait for the answer:\n\n" +
+		ask + "\n\n" +
+		"Use \"type\":\"text\" (or omit \"options\") for free-form answers.\n\n" +
+		"When the step is complete, emit:\n\n" + p.FinishedMarker()
+}
+
+// DetectQuestions finds question markers in text and returns the parsed
+// questions. Markers whose payload is not valid JSON are skipped.
+func (p *Protocol) DetectQuestions(text string) []Question {
+	var questions []Question
+	for _, match := range p.question.FindAllStringSubmatch(text, -1) {
+		var payload struct {
+			Questions []struct {
+				Question string `json:"question"`
+				Header   string `json:"header"`
+				Type     string `json:"type"`
+				Options  []any  `json:"options"`
+			} `json:"questions"`
+		}
+		if err := json.Unmarshal([]byte(match[1]), &payload); err != nil {
+			continue
+		}
+		for _, q := range payload.Questions {
+			options, dropped := normalizeOptions(q.Options)
+			qt := QuestionTypeText
+			if q.Type == string(QuestionTypeChoice) && len(options) > 0 {
+				qt = QuestionTypeChoice
+			}
+			questions = append(questions, Question{
+				Question:       q.Question,
+				Header:         q.Header,
+				Type:           qt,
+				Options:        options,
+				DroppedOptions: dropped,
+			})
+		}
+	}
+	return questions
+}
+
+// DetectFinished reports whether text contains the finished marker.
+func (p *Protocol) DetectFinished(text string) bool {
+	return p.finished.MatchString(text)
+}
+
+// DetectGoto returns the target step name if text contains a goto marker,
+// and whether one was found.
+func (p *Protocol) DetectGoto(text string) (string, bool) {
+	m := p.gotoStep.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	return strings.TrimSpace(m[1]), true
+}
+
+// StripFinishedTag removes the finished marker from text before display.
+func (p *Protocol) StripFinishedTag(text string) string {
+	return strings.TrimSpace(p.finished.ReplaceAllString(text, ""))
+}
+
+// StripMarkers removes finished, goto and question markers from text before
+// display.
+func (p *Protocol) StripMarkers(text string) string {
+	text = p.finished.ReplaceAllString(text, "")
+	text = p.gotoStep.ReplaceAllString(text, "")
+	text = p.question.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+// MarkerKind identifies a protocol marker found in agent output.
+type MarkerKind string
+
+const (
+	MarkerQuestion MarkerKind = "question"
+	MarkerFinished MarkerKind = "finished"
+	MarkerGoto     MarkerKind = "goto"
+)
+
+// Marker is a protocol marker removed by StripMarkersWithPositions. Offset is
+// the byte position in the stripped text where the marker stood, so a caller
+// can render an inline token there instead.
+type Marker struct {
+	Kind   MarkerKind
+	Raw    string // the marker text as the agent wrote it
+	Offset int
+}
+
+// StripMarkersWithPositions is StripMarkers that also reports where each
+// marker was, in order of appearance. Offsets index the returned text.
+func (p *Protocol) StripMarkersWithPositions(text string) (string, []Marker) {
+	type span struct {
+		kind       MarkerKind
+		start, end int
+	}
+	var spans []span
+	for _, m := range []struct {
+		kind MarkerKind
+		re   *regexp.Regexp
+	}{{MarkerFinished, p.finished}, {MarkerGoto, p.gotoStep}, {MarkerQuestion, p.question}} {
+		for _, loc := range m.re.FindAllStringIndex(text, -1) {
+			spans = append(spans, span{m.kind, loc[0], loc[1]})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	var markers []Marker
+	pos := 0
+	for _, s := range spans {
+		if s.start < pos {
+			continue // nested inside a marker already removed
+		}
+		b.WriteString(text[pos:s.start])
+		markers = append(markers, Marker{Kind: s.kind, Raw: text[s.start:s.end], Offset: b.Len()})
+		pos = s.end
+	}
+	b.WriteString(text[pos:])
+
+	stripped := b.String()
+	lead := len(stripped) - len(strings.TrimLeftFunc(stripped, unicode.IsSpace))
+	trimmed := strings.TrimSpace(stripped)
+	for i := range markers {
+		markers[i].Offset = min(max(markers[i].Offset-lead, 0), len(trimmed))
+	}
+	return trimmed, markers
+}
@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+const fencedQuestion = "Before I continue:\n```question\n" +
+	`{"questions":[{"question":"Which database?","header":"Storage"}]}` +
+	"\n```\n"
+
+func TestNewProtocol_RejectsUnknownStyle(t *testing.T) {
+	_, err := NewProtocol("xml")
+	require.ErrorContains(t, err, `unsupported question style "xml"`)
+}
+
+func TestNewProtocol_EmptyStyleIsComment(t *testing.T) {
+	p, err := NewProtocol("")
+	require.NoError(t, err)
+	require.Equal(t, config.QuestionStyleComment, p.Style())
+	require.Equal(t, "<!-- FINISHED -->", p.FinishedMarker())
+}
+
+func TestProtocol_FenceStyleMarkers(t *testing.T) {
+	p, err := NewProtocol(config.QuestionStyleFence)
+	require.NoError(t, err)
+
+	questions := p.DetectQuestions(fencedQuestion)
+	require.Len(t, questions, 1)
+	require.Equal(t, "Which database?", questions[0].Question)
+	require.Equal(t, "Storage", questions[0].Header)
+
+	require.True(t, p.DetectFinished("All done.\n```finished\n```"))
+	require.False(t, p.DetectFinished("All done. <!-- FINISHED -->"))
+
+	step, ok := p.DetectGoto("```goto\nrequirements\n```")
+	require.True(t, ok)
+	require.Equal(t, "requirements", step)
+
+	require.Equal(t, "Before I continue:", p.StripMarkers(fencedQuestion+"```finished\n```"))
+	require.Empty(t, DefaultProtocol.DetectQuestions(fencedQuestion), "the comment protocol ignores fenced markers")
+}
+
+func TestProtocol_InstructionsRoundTrip(t *testing.T) {
+	for _, style := range []string{config.QuestionStyleComment, config.QuestionStyleFence} {
+		t.Run(style, func(t *testing.T) {
+			p, err := NewProtocol(style)
+			require.NoError(t, err)
+
+			instructions := p.Instructions()
+			questions := p.DetectQuestions(instructions)
+			require.Len(t, questions, 1, "the example in the instructions must be detectable")
+			require.Equal(t, QuestionTypeChoice, questions[0].Type)
+			require.True(t, p.DetectFinished(instructions))
+		})
+	}
+}
+
+func TestRunSteps_FenceProtocolEndToEnd(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.Protocol.QuestionStyle = config.QuestionStyleFence
+
+	r := &scriptedRunner{turns: [][]Event{
+		{{Type: "system", Data: map[string]any{"session_id": "sess-1"}}, assistantText(fencedQuestion)},
+		{assistantText("Section written.\n```finished\n```"), resultEvent("sess-1")},
+	}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview"}, RequiresFinished: true}}
+	var asked []Question
+	answer := func(qs []Question) string { asked = qs; return "postgres" }
+	var shown []string
+	onText := func(s string) { shown = append(shown, s) }
+
+	require.NoError(t, RunSteps(t.Context(), r, steps, cfg, t.TempDir(), onText, answer))
+
+	require.Len(t, asked, 1)
+	require.Equal(t, "Which database?", asked[0].Question)
+	require.Len(t, r.calls, 2)
+	require.Equal(t, "postgres", r.calls[1].Prompts.User)
+	require.Equal(t, "Section written.", shown[len(shown)-1])
+}
+
+func TestRunSteps_FenceProtocolNudgeUsesFencedMarker(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.Protocol.QuestionStyle = config.QuestionStyleFence
+
+	r := &scriptedRunner{turns: [][]Event{
+		{assistantText("Section written. <!-- FINISHED -->"), resultEvent("sess-1")},
+		{assistantText("```finished\n```"), resultEvent("sess-1")},
+	}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview"}, RequiresFinished: true}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, cfg, t.TempDir(), nil, nil))
+
+	require.Len(t, r.calls, 2)
+	require.Contains(t, r.calls[1].Prompts.User, "```finished\n```")
+}
+
+func TestRunSteps_UnknownQuestionStyleFails(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.Protocol.QuestionStyle = "xml"
+	r := &scriptedRunner{}
+
+	err := RunSteps(t.Context(), r, []Step{{Prompts: Prompts{User: "go"}}}, cfg, t.TempDir(), nil, nil)
+	require.ErrorContains(t, err, "unsupported question style")
+	require.Empty(t, r.calls)
+}
+
+func TestRunSteps_AppendSystemCarriesProtocolInstructions(t *testing.T) {
+	cfg := config.NewDefault()
+	cfg.Protocol.QuestionStyle = config.QuestionStyleFence
+
+	r := &scriptedRunner{turns: [][]Event{{assistantText("```finished\n```"), resultEvent("sess-1")}}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview", System: "You are a spec writer."}}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, cfg, t.TempDir(), nil, nil))
+
+	require.Len(t, r.calls, 1)
+	require.Equal(t, "You are a spec writer.", r.calls[0].Prompts.System)
+	appended := r.calls[0].Prompts.AppendSystem
+	require.Contains(t, appended, "```question\n")
+	require.Contains(t, appended, "```finished\n```")
+	require.NotContains(t, appended, "<!--QUESTION:")
+}
+
+func TestRunSteps_EmptySystemKeepsAgentDefault(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{{assistantText("<!-- FINISHED -->"), resultEvent("sess-1")}}}
+	steps := []Step{{Prompts: Prompts{User: "write the overview"}}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, nil))
+
+	require.Len(t, r.calls, 1)
+	require.Empty(t, r.calls[0].Prompts.System)
+	require.Contains(t, r.calls[0].Prompts.AppendSystem, "<!-- FINISHED -->")
+}
@@ -2,22 +2,30 @@
 package runner
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"regexp"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/jumppad-labs/spektacular/internal/config"
 )
 
-var questionPattern = regexp.MustCompile(`<!--QUESTION:([\s\S]*?)-->`)
-
 // Runner is the interface that all agent backends must implement.
 type Runner interface {
 	// Run starts the agent with the given options and returns a channel of
 	// events and an error channel. The caller must drain both channels;
-	// the event channel is closed when the agent finishes.
-	Run(opts RunOptions) (<-chan Event, <-chan error)
+	// the event channel is closed when the agent finishes. Cancelling ctx
+	// should stop the agent, after which both channels are still closed.
+	Run(ctx context.Context, opts RunOptions) (<-chan Event, <-chan error)
+}
+
+// Resumer is implemented by runners that can report whether they support
+// resuming a session by ID. Runners that do not implement it are assumed to
+// support resume whenever they report a session ID.
+type Resumer interface {
+	SupportsResume() bool
 }
 
 // Event is a single parsed event from an agent's output stream.
@@ -104,91 +112,76 @@ type Question struct {
 	Question string
 	Header   string
 	Type     QuestionType
-	Options  []map[string]any
+	Options  []Option
+	// DroppedOptions counts options the agent sent that were left out of
+	// Options: entries with no usable label, and any beyond MaxOptions.
+	DroppedOptions int
+	// Reordered is set when Options were put back into the order a previous
+	// ask of the same question presented them in.
+	Reordered bool
 }
 
 // detectQuestions finds <!--QUESTION:{...}--> markers in text and returns parsed questions.
-func detectQuestions(text string) []Question {
-	var questions []Question
-	for _, match := range questionPattern.FindAllStringSubmatch(text, -1) {
-		var payload struct {
-			Questions []struct {
-				Question string           `json:"question"`
-				Header   string           `json:"header"`
-				Type     string           `json:"type"`
-				Options  []map[string]any `json:"options"`
-			} `json:"questions"`
-		}
-		if err := json.Unmarshal([]byte(match[1]), &payload); err != nil {
-			continue
-		}
-		for _, q := range payload.Questions {
-			qt := QuestionTypeText
-			if q.Type == string(QuestionTypeChoice) && len(q.Options) > 0 {
-				qt = QuestionTypeChoice
-			}
-			questions = append(questions, Question{
-				Question: q.Question,
-				Header:   q.Header,
-				Type:     qt,
-				Options:  q.Options,
-			})
-		}
-	}
-	return questions
-}
+func detectQuestions(text string) []Question { return DefaultProtocol.DetectQuestions(text) }
 
 // DetectQuestions is the exported wrapper used by other packages.
 func DetectQuestions(text string) []Question { return detectQuestions(text) }
 
-var finishedPattern = regexp.MustCompile(`<!--\s*FINISHED\s*-->`)
-var gotoPattern = regexp.MustCompile(`<!--\s*GOTO:\s*([\w][\w\s-]*?)\s*-->`)
-
 // DetectFinished reports whether the agent output contains a <!-- FINISHED --> marker.
-func DetectFinished(text string) bool {
-	return finishedPattern.MatchString(text)
-}
+func DetectFinished(text string) bool { return DefaultProtocol.DetectFinished(text) }
 
 // DetectGoto returns the target step name if the agent output contains a
 // <!-- GOTO: step name --> marker, and whether one was found.
-func DetectGoto(text string) (string, bool) {
-	m := gotoPattern.FindStringSubmatch(text)
-	if m == nil {
-		return "", false
-	}
-	return strings.TrimSpace(m[1]), true
-}
+func DetectGoto(text string) (string, bool) { return DefaultProtocol.DetectGoto(text) }
 
 // StripFinishedTag removes the <!-- FINISHED --> marker from text before display.
-func StripFinishedTag(text string) string {
-	return strings.TrimSpace(finishedPattern.ReplaceAllString(text, ""))
-}
+func StripFinishedTag(text string) string { return DefaultProtocol.StripFinishedTag(text) }
 
 // StripMarkers removes <!-- FINISHED -->, <!-- GOTO:... -->, and <!--QUESTION:...--> markers
 // from text before display.
-func StripMarkers(text string) string {
-	text = finishedPattern.ReplaceAllString(text, "")
-	text = gotoPattern.ReplaceAllString(text, "")
-	text = questionPattern.ReplaceAllString(text, "")
-	return strings.TrimSpace(text)
+func StripMarkers(text string) string { return DefaultProtocol.StripMarkers(text) }
+
+// StripMarkersWithPositions is StripMarkers that also reports where each
+// marker was, in order of appearance. Offsets index the returned text.
+func StripMarkersWithPositions(text string) (string, []Marker) {
+	return DefaultProtocol.StripMarkersWithPositions(text)
 }
 
 // Prompts bundles the user prompt and system prompt for an agent invocation.
 type Prompts struct {
 	User   string // initial user message
 	System string // system prompt; empty uses the agent's default
+	// AppendSystem is appended to the system prompt in effect, whether that
+	// is System or the agent's default. RunSteps sets it to the protocol
+	// instructions.
+	AppendSystem string
 }
 
 // Step defines one agent step in a multi-step pipeline.
 type Step struct {
-	Prompts Prompts
-	LogFile string // path to debug log file; empty disables logging
+	Name        string // step name, used for per-step log files; may be empty
+	Prompts     Prompts
+	Attachments []Attachment // files appended to the first turn's user prompt
+	LogFile     string       // path to debug log file; empty disables logging
+	// RequiresFinished makes the step complete only on <!-- FINISHED -->. A
+	// turn that ends without it gets one nudge turn before the step fails.
+	RequiresFinished bool
+}
+
+// finishNudge is sent when a RequiresFinished step's turn ends without the
+// finished marker.
+func (p *Protocol) finishNudge() string {
+	return "If the section is complete, emit " + p.FinishedMarker() + "; otherwise continue."
 }
 
 // RunSteps executes a sequence of Steps in order. Within each step, questions are answered
 // by calling onQuestion and the session is resumed. Steps advance on <!-- FINISHED --> or
-// on a natural result event. Returns an error if any step fails.
+// on a natural result event, except RequiresFinished steps, which only advance once the
+// agent emits the marker. Markers follow cfg.Protocol.QuestionStyle, whose
+// instructions go out as each step's Prompts.AppendSystem. Returns an error if
+// any step fails, or ctx's error once it is cancelled.
 func RunSteps(
+	ctx context.Context,
 	r Runner,
 	steps []Step,
 	cfg config.Config,
@@ -196,16 +189,75 @@ func RunSteps(
 	onText func(string),
 	onQuestion func([]Question) string,
 ) error {
-	for _, step := range steps {
-		if err := runStep(r, step, cfg, cwd, onText, onQuestion); err != nil {
+	protocol, err := ProtocolFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	for i, step := range steps {
+		if cfg.Debug.SplitSteps && step.LogFile != "" {
+			stepLog := StepLogFile(step.LogFile, i+1, step.Name)
+			if err := logStepBoundary(step.LogFile, i+1, step.Name, stepLog); err != nil {
+				return err
+			}
+			step.LogFile = stepLog
+		}
+		if err := runStep(ctx, r, protocol, step, cfg, cwd, onText, onQuestion); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// NoInput is the onQuestion callback for fire-and-forget runs: every
+// question is answered with "", which runStep turns into an instruction to
+// proceed on the agent's own judgement.
+func NoInput([]Question) string { return "" }
+
+// StepLogFile derives the per-step log path used when debug.split_steps is
+// enabled: "<base>_step3_acceptance-criteria.log" for the third step of a run
+// logging to "<base>.log". The name part is omitted for unnamed steps.
+func StepLogFile(base string, index int, name string) string {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if ext == "" {
+		ext = ".log"
+	}
+	slug := stepSlug(name)
+	if slug == "" {
+		return fmt.Sprintf("%s_step%d%s", stem, index, ext)
+	}
+	return fmt.Sprintf("%s_step%d_%s%s", stem, index, slug, ext)
+}
+
+// stepSlug lowercases name and maps every rune outside [a-z0-9] to "-", so a
+// step name can never add a directory to the log path. Runs of "-" collapse
+// and leading or trailing ones are dropped.
+func stepSlug(name string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+	return strings.Join(strings.FieldsFunc(mapped, func(r rune) bool { return r == '-' }), "-")
+}
+
+// logStepBoundary appends a marker to the combined log so a split run can
+// still be followed end to end from one file.
+func logStepBoundary(combined string, index int, name, stepLog string) error {
+	f, err := os.OpenFile(combined, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening debug log: %w", err)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "=== step %d: %s (log: %s) ===\n", index, name, filepath.Base(stepLog))
+	return err
+}
+
 func runStep(
+	ctx context.Context,
 	r Runner,
+	p *Protocol,
 	step Step,
 	cfg config.Config,
 	cwd string,
@@ -214,32 +266,70 @@ func runStep(
 ) error {
 	sessionID := ""
 	currentUser := step.Prompts.User
+	// Attachments only accompany the first turn; resumed turns rely on the
+	// session already holding their content.
+	attachments := step.Attachments
+	history := optionHistory{}
+	nudged := false
+	threshold := LargeWriteThreshold(cfg)
+	confirmed := map[string]bool{}
+	loops := newLoopDetector(MaxRepeats(cfg))
 
 	for {
 		var questionsFound []Question
-		var stepDone bool
-
-		events, errc := r.Run(RunOptions{
-			Prompts:   Prompts{User: currentUser, System: step.Prompts.System},
-			Config:    cfg,
-			SessionID: sessionID,
-			CWD:       cwd,
-			LogFile:   step.LogFile,
+		var largeWrites []LargeWrite
+		var turnText strings.Builder
+		var stepDone, finished bool
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		opts, warnings, err := PrepareOptions(RunOptions{
+			Prompts:     Prompts{User: currentUser, System: step.Prompts.System, AppendSystem: p.Instructions()},
+			Attachments: attachments,
+			Config:      cfg,
+			SessionID:   sessionID,
+			CWD:         cwd,
+			LogFile:     step.LogFile,
 		})
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(opts.Prompts.User) == "" {
+			return fmt.Errorf("step %s has an empty prompt; refusing to start the agent with nothing to do", stepLabel(step))
+		}
+		if onText != nil {
+			for _, w := range warnings {
+				onText("warning: " + w + "\n")
+			}
+		}
+		attachments = nil
 
-		for event := range events {
+		events, errc := r.Run(ctx, opts)
+
+		for event := range eventsUntilDone(ctx, events, errc) {
 			if id := event.SessionID(); id != "" {
 				sessionID = id
 			}
 			if text := event.TextContent(); text != "" {
-				if DetectFinished(text) {
+				if p.DetectFinished(text) {
+					// Keep draining: text after the marker in the same
+					// message is still shown before the step advances.
 					stepDone = true
+					finished = true
 				}
-				displayText := StripFinishedTag(text)
+				displayText := p.StripFinishedTag(text)
 				if onText != nil && displayText != "" {
 					onText(displayText)
 				}
-				questionsFound = append(questionsFound, DetectQuestions(text)...)
+				questionsFound = append(questionsFound, p.DetectQuestions(text)...)
+				turnText.WriteString(p.StripMarkers(text) + "\n")
+			}
+			for _, w := range DetectLargeWrites(event, threshold) {
+				largeWrites = append(largeWrites, w)
+				if onText != nil {
+					onText("warning: " + w.Warning() + "\n")
+				}
 			}
 			if event.IsResult() {
 				if event.IsError() {
@@ -249,49 +339,242 @@ func runStep(
 			}
 		}
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := <-errc; err != nil {
 			return fmt.Errorf("runner error: %w", err)
 		}
+		if err := loops.observe(turnText.String(), questionsFound); err != nil {
+			return fmt.Errorf("step %s: %w", stepLabel(step), err)
+		}
 
 		if !stepDone && len(questionsFound) > 0 && onQuestion != nil {
+			for i := range questionsFound {
+				history.stabilize(&questionsFound[i])
+			}
 			answer := onQuestion(questionsFound)
+			if strings.TrimSpace(answer) == "" {
+				answer = noAnswer
+			}
+			if answer, err = fileAnswer(cfg, cwd, answer, onText); err != nil {
+				return err
+			}
+			if !canResume(r, sessionID) {
+				// Without a session the agent would see a bare answer with
+				// no idea what it answers, so restate the step and questions.
+				sessionID = ""
+				currentUser = recapPrompt(step.Prompts.User, questionsFound, answer)
+				attachments = step.Attachments
+				continue
+			}
 			currentUser = answer
 			continue
 		}
 
+		if cfg.LargeWrites.Confirm && canResume(r, sessionID) {
+			// Only the session that made the writes can vouch for them, and
+			// each file is queried once so a confirmed rewrite cannot loop.
+			var unconfirmed []LargeWrite
+			for _, w := range largeWrites {
+				if !confirmed[w.Path] {
+					confirmed[w.Path] = true
+					unconfirmed = append(unconfirmed, w)
+				}
+			}
+			if len(unconfirmed) > 0 {
+				currentUser = confirmWritesPrompt(p, unconfirmed)
+				continue
+			}
+		}
+
+		if step.RequiresFinished && !finished {
+			if nudged {
+				return fmt.Errorf("step %s ended without emitting %s", stepLabel(step), p.FinishedMarker())
+			}
+			nudged = true
+			if !canResume(r, sessionID) {
+				sessionID = ""
+				currentUser = step.Prompts.User + "\n\n" + p.finishNudge()
+				attachments = step.Attachments
+				continue
+			}
+			currentUser = p.finishNudge()
+			continue
+		}
+
 		return nil
 	}
 }
 
-// PromptWithHeader is the user prompt template with a custom content section header.
-// Args: header, content.
-var PromptWithHeader = `Additional project knowledge, architectural context, and past learnings can be found in '.spektacular/knowledge/'. Use your available tools to explore this directory as needed.
+// eventsUntilDone relays events until the channel closes or ctx is
+// cancelled. On cancellation the rest of the runner's output is drained in
+// the background so a runner still winding down never blocks on a send.
+func eventsUntilDone(ctx context.Context, events <-chan Event, errc <-chan error) <-chan Event {
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				go func() {
+					for range events {
+					}
+					<-errc
+				}()
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+	return out
+}
 
----
+// noAnswer is sent in place of an empty answer so a resumed turn never
+// starts the agent with an empty prompt.
+const noAnswer = "No answer was given. Proceed using your best judgement and note any assumptions you make."
 
-# %s
+// stepLabel names a step for error messages.
+func stepLabel(step Step) string {
+	if step.Name == "" {
+		return "(unnamed)"
+	}
+	return fmt.Sprintf("%q", step.Name)
+}
 
-%s`
+// canResume reports whether the next turn can resume sessionID.
+func canResume(r Runner, sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	if rs, ok := r.(Resumer); ok {
+		return rs.SupportsResume()
+	}
+	return true
+}
 
-// PromptPlan is the user prompt template for the planner, including the plan directory.
-// Args: planDir, specContent.
-var PromptPlan = `Additional project knowledge, architectural context, and past learnings can be found in '.spektacular/knowledge/'. Use your available tools to explore this directory as needed.
+// recapPrompt builds a self-contained resume turn for when the session
+// cannot be resumed: the step's original request, the questions the agent
+// asked and the user's answer.
+func recapPrompt(original string, questions []Question, answer string) string {
+	var b strings.Builder
+	b.WriteString("The previous session could not be resumed, so here is a recap of this step.\n\n")
+	b.WriteString("## Original request\n\n")
+	b.WriteString(strings.TrimSpace(original))
+	b.WriteString("\n\n## Your questions\n\n")
+	for _, q := range questions {
+		if q.Header != "" {
+			fmt.Fprintf(&b, "- [%s] %s\n", q.Header, q.Question)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", q.Question)
+		}
+	}
+	b.WriteString("\n## User's answer\n\n")
+	b.WriteString(strings.TrimSpace(answer))
+	b.WriteString("\n")
+	return b.String()
+}
 
-Write all plan output files to this exact directory: '%s'
+// maxHintFiles caps how many knowledge files the hint lists by name.
+const maxHintFiles = 20
+
+// KnowledgeHint returns the prompt paragraph pointing the agent at the
+// project's configured knowledge sources (the default .spektacular/knowledge
+// source when none are configured), naming the files they actually contain.
+// projectPath is the project root; relative source locations resolve against
+// it, and paths inside it are shown project-relative. It returns "" when no
+// source holds any files, so the agent does not waste turns exploring a path
+// that does not exist.
+func KnowledgeHint(projectPath string, cfg config.Config) string {
+	var dirs, files []string
+	for _, src := range cfg.Knowledge.WithDefaults(projectPath).Sources {
+		if src.Provider != config.ProviderFile {
+			continue
+		}
+		root := src.Config.Location
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(projectPath, root)
+		}
+		found := 0
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			files = append(files, displayPath(projectPath, path))
+			found++
+			return nil
+		})
+		if found > 0 {
+			dirs = append(dirs, "'"+displayPath(projectPath, root)+"/'")
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
 
----
+	var b strings.Builder
+	fmt.Fprintf(&b, "Additional project knowledge, architectural context, and past learnings can be found in %s. Use your available tools to read these files as needed:\n", strings.Join(dirs, ", "))
+	for _, f := range files[:min(len(files), maxHintFiles)] {
+		fmt.Fprintf(&b, "\n- %s", f)
+	}
+	if extra := len(files) - maxHintFiles; extra > 0 {
+		fmt.Fprintf(&b, "\n- …and %d more", extra)
+	}
+	return b.String()
+}
 
-# Specification to Plan
+// displayPath returns path relative to projectPath when it lies inside the
+// project, and unchanged otherwise.
+func displayPath(projectPath, path string) string {
+	rel, err := filepath.Rel(projectPath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
 
-%s`
+// BuildPromptWithHeader builds a user prompt with a custom content section
+// header, preceded by the knowledge hint for the project when there is one.
+func BuildPromptWithHeader(projectPath string, cfg config.Config, header, content string) string {
+	return withKnowledgeHint(projectPath, cfg, fmt.Sprintf("# %s\n\n%s", header, content))
+}
+
+// BuildPlanPrompt builds the planner's user prompt: the knowledge hint (when
+// the project has knowledge), the plan output directory, and the spec.
+func BuildPlanPrompt(projectPath string, cfg config.Config, planDir, specContent string) string {
+	body := fmt.Sprintf("Write all plan output files to this exact directory: '%s'\n\n---\n\n# Specification to Plan\n\n%s", planDir, specContent)
+	hint := KnowledgeHint(projectPath, cfg)
+	if hint == "" {
+		return body
+	}
+	return hint + "\n\n" + body
+}
+
+func withKnowledgeHint(projectPath string, cfg config.Config, body string) string {
+	hint := KnowledgeHint(projectPath, cfg)
+	if hint == "" {
+		return body
+	}
+	return hint + "\n\n---\n\n" + body
+}
 
 // RunOptions holds parameters for running an agent.
 type RunOptions struct {
-	Prompts   Prompts
-	Config    config.Config
-	SessionID string
-	CWD       string
-	LogFile   string // path to debug log file; empty disables logging
-	Model     string // model override; empty uses the agent default
+	Prompts     Prompts
+	Attachments []Attachment // resolved into Prompts.User by PrepareOptions
+	Config      config.Config
+	SessionID   string
+	CWD         string
+	LogFile     string // path to debug log file; empty disables logging
+	Model       string // model override; empty uses the agent default
+	// Env adds or overrides agent environment variables for this run, on top
+	// of Config.AgentEnv; see AgentEnviron.
+	Env map[string]string
 }
-
@@ -1,9 +1,14 @@
 package runner
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/jumppad-labs/spektacular/internal/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -119,23 +124,126 @@ func TestDetectQuestions_MultilineMarker(t *testing.T) {
 	require.Len(t, questions, 1)
 }
 
+func TestStripMarkersWithPositions_InterleavedMarkers(t *testing.T) {
+	ask := `<!--QUESTION:{"questions":[{"question":"Which database?"}]}-->`
+	text := "  Intro. " + ask + " Middle. <!-- GOTO: requirements --> Then " + ask + " done. <!-- FINISHED -->\n"
+
+	stripped, markers := StripMarkersWithPositions(text)
+	require.Equal(t, StripMarkers(text), stripped)
+	require.Equal(t, "Intro.  Middle.  Then  done.", stripped)
+
+	require.Len(t, markers, 4)
+	kinds := []MarkerKind{markers[0].Kind, markers[1].Kind, markers[2].Kind, markers[3].Kind}
+	require.Equal(t, []MarkerKind{MarkerQuestion, MarkerGoto, MarkerQuestion, MarkerFinished}, kinds)
+	require.Equal(t, ask, markers[0].Raw)
+	require.Equal(t, "<!-- GOTO: requirements -->", markers[1].Raw)
+
+	require.Equal(t, "Intro. ", stripped[:markers[0].Offset])
+	require.Equal(t, "Intro.  Middle. ", stripped[:markers[1].Offset])
+	require.Equal(t, "Intro.  Middle.  Then ", stripped[:markers[2].Offset])
+	require.Equal(t, len(stripped), markers[3].Offset, "a marker in trimmed trailing space sits at the end")
+}
+
+func TestStripMarkersWithPositions_NoMarkers(t *testing.T) {
+	stripped, markers := StripMarkersWithPositions("  plain text \n")
+	require.Equal(t, "plain text", stripped)
+	require.Empty(t, markers)
+}
+
+func TestStripMarkersWithPositions_LeadingMarker(t *testing.T) {
+	stripped, markers := StripMarkersWithPositions("<!-- FINISHED -->\nAll done.")
+	require.Equal(t, "All done.", stripped)
+	require.Len(t, markers, 1)
+	require.Equal(t, 0, markers[0].Offset)
+}
+
 // ---------------------------------------------------------------------------
-// buildPrompt tests
+// Prompt builder tests
 // ---------------------------------------------------------------------------
 
-func TestPromptWithHeader_ContainsSpecAndKnowledgeHint(t *testing.T) {
-	prompt := fmt.Sprintf(PromptWithHeader, "Specification to Plan", "my spec")
+// writeKnowledge creates a project with the given files under the default
+// knowledge directory.
+func writeKnowledge(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(dir, ".spektacular", "knowledge", f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+	}
+	return dir
+}
+
+func TestBuildPromptWithHeader_ContainsSpecAndKnowledgeHint(t *testing.T) {
+	dir := writeKnowledge(t, "conventions.md", "architecture/README.md")
+
+	prompt := BuildPromptWithHeader(dir, config.NewDefault(), "Specification to Plan", "my spec")
 	require.Contains(t, prompt, "my spec")
 	require.Contains(t, prompt, ".spektacular/knowledge/")
+	require.Contains(t, prompt, "- .spektacular/knowledge/conventions.md")
+	require.Contains(t, prompt, "- .spektacular/knowledge/architecture/README.md")
 }
 
-func TestPromptWithHeader_UsesCustomHeader(t *testing.T) {
-	prompt := fmt.Sprintf(PromptWithHeader, "Implementation Plan", "plan content")
+func TestBuildPromptWithHeader_UsesCustomHeader(t *testing.T) {
+	prompt := BuildPromptWithHeader(t.TempDir(), config.NewDefault(), "Implementation Plan", "plan content")
 	require.Contains(t, prompt, "# Implementation Plan")
 	require.Contains(t, prompt, "plan content")
 	require.NotContains(t, prompt, "Specification to Plan")
 }
 
+func TestKnowledgeHint_MissingDirOmitsHint(t *testing.T) {
+	dir := t.TempDir()
+	require.Equal(t, "", KnowledgeHint(dir, config.NewDefault()))
+	require.Equal(t, "# Plan\n\nbody", BuildPromptWithHeader(dir, config.NewDefault(), "Plan", "body"))
+}
+
+func TestKnowledgeHint_EmptyDirOmitsHint(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".spektacular", "knowledge", "learnings"), 0755))
+
+	require.Equal(t, "", KnowledgeHint(dir, config.NewDefault()))
+	require.NotContains(t, BuildPlanPrompt(dir, config.NewDefault(), "/plans/x", "spec"), "knowledge")
+}
+
+func TestKnowledgeHint_CapsListedFiles(t *testing.T) {
+	var files []string
+	for i := 0; i < maxHintFiles+3; i++ {
+		files = append(files, fmt.Sprintf("note%02d.md", i))
+	}
+	hint := KnowledgeHint(writeKnowledge(t, files...), config.NewDefault())
+	require.Equal(t, maxHintFiles, strings.Count(hint, "- .spektacular/knowledge/note"))
+	require.Contains(t, hint, "…and 3 more")
+}
+
+func TestKnowledgeHint_UsesConfiguredSources(t *testing.T) {
+	dir := t.TempDir()
+	shared := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "knowledge"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "knowledge", "adr-1.md"), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "style.md"), []byte("x"), 0644))
+	// The default directory is ignored once sources are configured.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".spektacular", "knowledge"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".spektacular", "knowledge", "old.md"), []byte("x"), 0644))
+
+	cfg := config.NewDefault()
+	cfg.Knowledge.Sources = []config.SourceConfig{
+		{Scope: "project", Provider: config.ProviderFile, Config: config.FileKnowledgeConfig{Location: "docs/knowledge"}},
+		{Scope: "team", Provider: config.ProviderFile, Config: config.FileKnowledgeConfig{Location: shared}},
+	}
+	hint := KnowledgeHint(dir, cfg)
+	require.Contains(t, hint, "'docs/knowledge/', '"+filepath.ToSlash(shared)+"/'")
+	require.Contains(t, hint, "- docs/knowledge/adr-1.md")
+	require.Contains(t, hint, "- "+filepath.ToSlash(filepath.Join(shared, "style.md")))
+	require.NotContains(t, hint, "old.md")
+}
+
+func TestBuildPlanPrompt_IncludesPlanDirAndSpec(t *testing.T) {
+	prompt := BuildPlanPrompt(writeKnowledge(t, "conventions.md"), config.NewDefault(), "/plans/feature", "the spec")
+	require.Contains(t, prompt, "'/plans/feature'")
+	require.Contains(t, prompt, "# Specification to Plan\n\nthe spec")
+	require.Less(t, strings.Index(prompt, "conventions.md"), strings.Index(prompt, "/plans/feature"))
+}
+
 // ---------------------------------------------------------------------------
 // NewRunner factory tests
 // ---------------------------------------------------------------------------
@@ -164,10 +272,290 @@ func TestNewRunner_ReturnsRunnerForRegisteredCommand(t *testing.T) {
 // stubRunner is a minimal runner for testing the registry.
 type stubRunner struct{}
 
-func (s *stubRunner) Run(_ RunOptions) (<-chan Event, <-chan error) {
+func (s *stubRunner) Run(_ context.Context, _ RunOptions) (<-chan Event, <-chan error) {
 	events := make(chan Event)
 	errc := make(chan error)
 	close(events)
 	close(errc)
 	return events, errc
 }
+
+// scriptedRunner replays one canned list of events per Run call and records
+// the options it was invoked with.
+type scriptedRunner struct {
+	turns [][]Event
+	calls []RunOptions
+}
+
+func (s *scriptedRunner) Run(_ context.Context, opts RunOptions) (<-chan Event, <-chan error) {
+	s.calls = append(s.calls, opts)
+	var turn []Event
+	if i := len(s.calls) - 1; i < len(s.turns) {
+		turn = s.turns[i]
+	}
+	events := make(chan Event, len(turn))
+	errc := make(chan error, 1)
+	for _, e := range turn {
+		events <- e
+	}
+	close(events)
+	errc <- nil
+	return events, errc
+}
+
+// assistantText builds an assistant event carrying a single text block.
+func assistantText(text string) Event {
+	return Event{Type: "assistant", Data: map[string]any{
+		"message": map[string]any{"content": []any{map[string]any{"type": "text", "text": text}}},
+	}}
+}
+
+// resultEvent builds a successful result event for the given session.
+func resultEvent(sessionID string) Event {
+	return Event{Type: "result", Data: map[string]any{"session_id": sessionID, "result": "done"}}
+}
+
+// ---------------------------------------------------------------------------
+// Per-step debug log tests
+// ---------------------------------------------------------------------------
+
+func TestStepLogFile(t *testing.T) {
+	require.Equal(t, "/logs/run_step3_acceptance-criteria.log", StepLogFile("/logs/run.log", 3, "acceptance_criteria"))
+	require.Equal(t, "/logs/run_step1_overview.txt", StepLogFile("/logs/run.txt", 1, "Overview"))
+	require.Equal(t, "/logs/run_step2.log", StepLogFile("/logs/run", 2, ""))
+	require.Equal(t, "/logs/run_step4_plan-write.log", StepLogFile("/logs/run.log", 4, "plan/write"))
+	require.Equal(t, "/logs/run_step5_etc-passwd.log", StepLogFile("/logs/run.log", 5, "../etc\\passwd"))
+	require.Equal(t, "/logs/run_step6.log", StepLogFile("/logs/run.log", 6, "//"))
+}
+
+func TestRunSteps_StopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	r := &scriptedRunner{}
+	err := RunSteps(ctx, r, []Step{{Prompts: Prompts{User: "go"}}}, config.NewDefault(), t.TempDir(), nil, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, r.calls, "no agent turn starts once cancelled")
+}
+
+// blockingRunner emits one event and then stalls until its context is
+// cancelled, like an agent mid-turn.
+type blockingRunner struct{ started chan struct{} }
+
+func (b *blockingRunner) Run(ctx context.Context, _ RunOptions) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(events)
+		events <- assistantText("working")
+		close(b.started)
+		<-ctx.Done()
+		errc <- ctx.Err()
+	}()
+	return events, errc
+}
+
+func TestRunSteps_CancelMidTurn(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	r := &blockingRunner{started: make(chan struct{})}
+	go func() {
+		<-r.started
+		cancel()
+	}()
+	err := RunSteps(ctx, r, []Step{{Prompts: Prompts{User: "go"}}}, config.NewDefault(), t.TempDir(), nil, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunSteps_SplitStepsGivesEachStepItsOwnLog(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "run.log")
+	cfg := config.NewDefault()
+	cfg.Debug.SplitSteps = true
+
+	r := &scriptedRunner{turns: [][]Event{{resultEvent("a")}, {resultEvent("b")}}}
+	steps := []Step{
+		{Name: "overview", Prompts: Prompts{User: "one"}, LogFile: base},
+		{Name: "acceptance_criteria", Prompts: Prompts{User: "two"}, LogFile: base},
+	}
+	require.NoError(t, RunSteps(t.Context(), r, steps, cfg, dir, nil, nil))
+
+	require.Len(t, r.calls, 2)
+	require.Equal(t, filepath.Join(dir, "run_step1_overview.log"), r.calls[0].LogFile)
+	require.Equal(t, filepath.Join(dir, "run_step2_acceptance-criteria.log"), r.calls[1].LogFile)
+
+	combined, err := os.ReadFile(base)
+	require.NoError(t, err)
+	require.Equal(t,
+		"=== step 1: overview (log: run_step1_overview.log) ===\n"+
+			"=== step 2: acceptance_criteria (log: run_step2_acceptance-criteria.log) ===\n",
+		string(combined))
+}
+
+func TestRunSteps_WithoutSplitStepsSharesOneLog(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "run.log")
+
+	r := &scriptedRunner{turns: [][]Event{{resultEvent("a")}, {resultEvent("b")}}}
+	steps := []Step{
+		{Name: "overview", Prompts: Prompts{User: "one"}, LogFile: base},
+		{Name: "requirements", Prompts: Prompts{User: "two"}, LogFile: base},
+	}
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), dir, nil, nil))
+
+	require.Equal(t, base, r.calls[0].LogFile)
+	require.Equal(t, base, r.calls[1].LogFile)
+	require.NoFileExists(t, base)
+}
+
+// ---------------------------------------------------------------------------
+// Resume recap tests
+// ---------------------------------------------------------------------------
+
+// noResumeRunner wraps a scriptedRunner and reports no resume support.
+type noResumeRunner struct{ *scriptedRunner }
+
+func (noResumeRunner) SupportsResume() bool { return false }
+
+func questionTurn(sessionID string) []Event {
+	ask := assistantText(`<!--QUESTION:{"questions":[{"question":"Which database?","header":"Storage"}]}-->`)
+	if sessionID == "" {
+		return []Event{ask}
+	}
+	return []Event{{Type: "system", Data: map[string]any{"session_id": sessionID}}, ask}
+}
+
+func TestRunSteps_ResumesWithBareAnswerWhenSessionKnown(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{questionTurn("sess-1"), {resultEvent("sess-1")}}}
+	steps := []Step{{Name: "plan", Prompts: Prompts{User: "write the plan"}}}
+	answer := func([]Question) string { return "postgres" }
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, answer))
+
+	require.Len(t, r.calls, 2)
+	require.Equal(t, "sess-1", r.calls[1].SessionID)
+	require.Equal(t, "postgres", r.calls[1].Prompts.User)
+}
+
+func TestRunSteps_RecapsWhenSessionIDDropped(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{questionTurn(""), {resultEvent("")}}}
+	steps := []Step{{Name: "plan", Prompts: Prompts{User: "write the plan"}}}
+	answer := func([]Question) string { return "postgres" }
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, answer))
+
+	require.Len(t, r.calls, 2)
+	resumed := r.calls[1]
+	require.Empty(t, resumed.SessionID)
+	require.Contains(t, resumed.Prompts.User, "could not be resumed")
+	require.Contains(t, resumed.Prompts.User, "write the plan")
+	require.Contains(t, resumed.Prompts.User, "- [Storage] Which database?")
+	require.Contains(t, resumed.Prompts.User, "postgres")
+}
+
+func TestRunSteps_RecapsWhenRunnerCannotResume(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.md"), []byte("spec body"), 0o644))
+	r := noResumeRunner{&scriptedRunner{turns: [][]Event{questionTurn("sess-1"), {resultEvent("sess-1")}}}}
+	steps := []Step{{
+		Name:        "plan",
+		Prompts:     Prompts{User: "write the plan"},
+		Attachments: []Attachment{{Path: "spec.md"}},
+	}}
+	answer := func([]Question) string { return "postgres" }
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), dir, nil, answer))
+
+	resumed := r.calls[1]
+	require.Empty(t, resumed.SessionID)
+	require.Contains(t, resumed.Prompts.User, "- [Storage] Which database?")
+	require.Contains(t, resumed.Prompts.User, "spec body", "attachments must be re-sent when the session is lost")
+}
+
+func TestRunSteps_NoStepsIsANoop(t *testing.T) {
+	r := &scriptedRunner{}
+	require.NoError(t, RunSteps(t.Context(), r, nil, config.NewDefault(), t.TempDir(), nil, nil))
+	require.Empty(t, r.calls)
+}
+
+func TestRunSteps_EmptyPromptFailsBeforeAgentStarts(t *testing.T) {
+	r := &scriptedRunner{}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "  \n", System: "be helpful"}}}
+
+	err := RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, nil)
+	require.ErrorContains(t, err, `step "overview" has an empty prompt`)
+	require.Empty(t, r.calls)
+}
+
+func TestRunSteps_EmptyAnswerResumesWithNoAnswerNote(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{questionTurn("sess-1"), {resultEvent("sess-1")}}}
+	steps := []Step{{Name: "plan", Prompts: Prompts{User: "write the plan"}}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, NoInput))
+
+	require.Len(t, r.calls, 2)
+	require.Equal(t, noAnswer, r.calls[1].Prompts.User)
+}
+
+// ---------------------------------------------------------------------------
+// FINISHED contract tests
+// ---------------------------------------------------------------------------
+
+func finishedTurn(sessionID string) []Event {
+	return []Event{assistantText("Section written. <!-- FINISHED -->"), resultEvent(sessionID)}
+}
+
+func TestRunSteps_RequiresFinishedNudgesOnce(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{
+		{assistantText("Here is a draft."), resultEvent("sess-1")},
+		finishedTurn("sess-1"),
+	}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview"}, RequiresFinished: true}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, nil))
+
+	require.Len(t, r.calls, 2)
+	require.Equal(t, "sess-1", r.calls[1].SessionID)
+	require.Equal(t, DefaultProtocol.finishNudge(), r.calls[1].Prompts.User)
+}
+
+func TestRunSteps_RequiresFinishedFailsAfterNudge(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{
+		{assistantText("Here is a draft."), resultEvent("sess-1")},
+		{assistantText("Still drafting."), resultEvent("sess-1")},
+	}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview"}, RequiresFinished: true}}
+
+	err := RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, nil)
+	require.ErrorContains(t, err, `step "overview" ended without emitting <!-- FINISHED -->`)
+	require.Len(t, r.calls, 2)
+}
+
+func TestRunSteps_RequiresFinishedNudgeRestatesStepWithoutSession(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{
+		{assistantText("Here is a draft."), resultEvent("")},
+		finishedTurn(""),
+	}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview"}, RequiresFinished: true}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, nil))
+
+	resumed := r.calls[1]
+	require.Empty(t, resumed.SessionID)
+	require.Contains(t, resumed.Prompts.User, "write the overview")
+	require.Contains(t, resumed.Prompts.User, DefaultProtocol.finishNudge())
+}
+
+func TestRunSteps_WithoutRequiresFinishedResultAdvances(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{{assistantText("Here is a draft."), resultEvent("sess-1")}}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview"}}}
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), nil, nil))
+	require.Len(t, r.calls, 1)
+}
+
+func TestRunSteps_TextAfterFinishedIsNotLost(t *testing.T) {
+	r := &scriptedRunner{turns: [][]Event{{
+		assistantText("Section written. <!-- FINISHED --> One more note."),
+		assistantText("And a trailing message."),
+		resultEvent("sess-1"),
+	}}}
+	steps := []Step{{Name: "overview", Prompts: Prompts{User: "write the overview"}, RequiresFinished: true}}
+	var shown []string
+	onText := func(s string) { shown = append(shown, s) }
+	require.NoError(t, RunSteps(t.Context(), r, steps, config.NewDefault(), t.TempDir(), onText, nil))
+
+	require.Equal(t, []string{"Section written.  One more note.", "And a trailing message."}, shown)
+	require.Len(t, r.calls, 1)
+}
@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+)
+
+// DefaultIgnoredPaths are the project-root-relative directories hidden from
+// the agent's file tools unless tool_access.ignore is configured. They hold
+// spektacular's own logs, run records and archives, which only waste the
+// agent's context. The spec and plan directories are deliberately absent.
+var DefaultIgnoredPaths = []string{
+	".spektacular/logs",
+	".spektacular/runs",
+	".spektacular/archive",
+}
+
+// restrictedTools are the agent file tools that ignored paths apply to.
+var restrictedTools = []string{"Read", "Grep", "Glob"}
+
+// IgnoredPaths returns the paths hidden from the agent's file tools for cfg.
+func IgnoredPaths(cfg config.Config) []string {
+	if cfg.ToolAccess.Ignore == nil {
+		return DefaultIgnoredPaths
+	}
+	return cfg.ToolAccess.Ignore
+}
+
+// DisallowedToolPatterns renders ignored paths as tool restriction rules of
+// the form "Read(./.spektacular/logs/**)", for backends that accept a list
+// of disallowed tool patterns.
+func DisallowedToolPatterns(ignored []string) []string {
+	var patterns []string
+	for _, p := range ignored {
+		path := "./" + strings.TrimSuffix(filepath.ToSlash(filepath.Clean(p)), "/")
+		for _, tool := range restrictedTools {
+			patterns = append(patterns, fmt.Sprintf("%s(%s/**)", tool, path))
+		}
+	}
+	return patterns
+}
+
+// PathIgnored reports whether path, absolute or relative to cwd, falls inside
+// one of the ignored directories (themselves relative to cwd). Backends that
+// execute file tools in-process call it before touching the filesystem.
+func PathIgnored(cwd, path string, ignored []string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	for _, p := range ignored {
+		rel, err := filepath.Rel(filepath.Join(cwd, p), path)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoredPaths_DefaultsAndOverride(t *testing.T) {
+	cfg := config.NewDefault()
+	require.Equal(t, DefaultIgnoredPaths, IgnoredPaths(cfg))
+
+	cfg.ToolAccess.Ignore = []string{"tmp"}
+	require.Equal(t, []string{"tmp"}, IgnoredPaths(cfg))
+
+	cfg.ToolAccess.Ignore = []string{}
+	require.Empty(t, IgnoredPaths(cfg))
+}
+
+func TestDisallowedToolPatterns(t *testing.T) {
+	require.Equal(t, []string{
+		"Read(./.spektacular/logs/**)",
+		"Grep(./.spektacular/logs/**)",
+		"Glob(./.spektacular/logs/**)",
+		"Read(./tmp/**)",
+		"Grep(./tmp/**)",
+		"Glob(./tmp/**)",
+	}, DisallowedToolPatterns([]string{".spektacular/logs/", "tmp"}))
+	require.Empty(t, DisallowedToolPatterns(nil))
+}
+
+func TestPathIgnored(t *testing.T) {
+	cwd := t.TempDir()
+
+	require.True(t, PathIgnored(cwd, ".spektacular/logs/run.log", DefaultIgnoredPaths))
+	require.True(t, PathIgnored(cwd, filepath.Join(cwd, ".spektacular", "archive"), DefaultIgnoredPaths))
+	require.True(t, PathIgnored(cwd, ".spektacular/runs/../runs/x.json", DefaultIgnoredPaths))
+
+	require.False(t, PathIgnored(cwd, ".spektacular/specs/billing.md", DefaultIgnoredPaths))
+	require.False(t, PathIgnored(cwd, ".spektacular/plans/billing/plan.md", DefaultIgnoredPaths))
+	require.False(t, PathIgnored(cwd, ".spektacular/logsheet.md", DefaultIgnoredPaths))
+	require.False(t, PathIgnored(cwd, "main.go", DefaultIgnoredPaths))
+	require.False(t, PathIgnored(cwd, ".spektacular/logs/run.log", nil))
+}
@@ -99,13 +99,13 @@ func TestFSMWalkFromNewToFinished(t *testing.T) {
 		"update_changelog",
 	}
 	for _, want := range linear {
-		require.NoError(t, wf.Next(), "transition to %s failed", want)
+		require.NoError(t, wf.Next(t.Context()), "transition to %s failed", want)
 		require.Equal(t, want, wf.Current(), "expected state %s after transition", want)
 	}
 
-	require.NoError(t, wf.Goto("update_repo_changelog"))
+	require.NoError(t, wf.Goto(t.Context(), "update_repo_changelog"))
 	require.Equal(t, "update_repo_changelog", wf.Current())
-	require.NoError(t, wf.Goto("finished"))
+	require.NoError(t, wf.Goto(t.Context(), "finished"))
 	require.Equal(t, "finished", wf.Current())
 }
 
@@ -120,24 +120,24 @@ func TestFSMLoopFromUpdateChangelogBackToAnalyze(t *testing.T) {
 
 	// Walk through to update_changelog the first time.
 	for _, want := range []string{"read_plan", "analyze", "implement", "test", "verify", "update_plan", "update_changelog"} {
-		require.NoError(t, wf.Next())
+		require.NoError(t, wf.Next(t.Context()))
 		require.Equal(t, want, wf.Current())
 	}
 
 	// Loop back via the multi-source edge: update_changelog → analyze.
-	require.NoError(t, wf.Goto("analyze"))
+	require.NoError(t, wf.Goto(t.Context(), "analyze"))
 	require.Equal(t, "analyze", wf.Current())
 
 	// Walk forward again to update_changelog.
 	for _, want := range []string{"implement", "test", "verify", "update_plan", "update_changelog"} {
-		require.NoError(t, wf.Next())
+		require.NoError(t, wf.Next(t.Context()))
 		require.Equal(t, want, wf.Current())
 	}
 
 	// Second exit: update_changelog → update_repo_changelog → finished.
-	require.NoError(t, wf.Goto("update_repo_changelog"))
+	require.NoError(t, wf.Goto(t.Context(), "update_repo_changelog"))
 	require.Equal(t, "update_repo_changelog", wf.Current())
-	require.NoError(t, wf.Goto("finished"))
+	require.NoError(t, wf.Goto(t.Context(), "finished"))
 	require.Equal(t, "finished", wf.Current())
 }
 
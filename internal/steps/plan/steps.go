@@ -1,6 +1,9 @@
 package plan
 
 import (
+	"maps"
+	"path/filepath"
+
 	"github.com/jumppad-labs/spektacular/internal/stepkit"
 	"github.com/jumppad-labs/spektacular/internal/store"
 	"github.com/jumppad-labs/spektacular/internal/workflow"
@@ -24,6 +27,16 @@ func ResearchFilePath(dir, name string) string {
 	return dir + "/" + name + "/research.md"
 }
 
+// PipedSpecFilePath returns the store-relative path a spec read from stdin is
+// kept at when it is not saved into the spec directory.
+func PipedSpecFilePath(dir, name string) string {
+	return dir + "/" + name + "/spec.md"
+}
+
+// SpecFileKey is the workflow data key holding a store-relative spec path
+// that replaces the default <spec dir>/<name>.md, set for piped specs.
+const SpecFileKey = "spec_file"
+
 // Steps returns the ordered step configs for a plan workflow.
 func Steps() []workflow.StepConfig {
 	return []workflow.StepConfig{
@@ -61,6 +74,13 @@ func buildResult(stepName, instanceName, primaryPath, instruction string) any {
 // writeStep is a one-liner wrapper around stepkit.WriteStepResult with the
 // plan strategy and result builder pre-applied. Step callbacks below call it.
 func writeStep(stepName, nextStep, templatePath string, data workflow.Data, out workflow.ResultWriter, st store.Store, cfg workflow.Config, extra map[string]any) error {
+	if specFile := stepkit.GetString(data, SpecFileKey); specFile != "" && st != nil {
+		extra = maps.Clone(extra)
+		if extra == nil {
+			extra = map[string]any{}
+		}
+		extra["spec_path"] = filepath.Join(st.Root(), specFile)
+	}
 	return stepkit.WriteStepResult(
 		stepkit.StepRequest{
 			StepName:     stepName,
@@ -149,7 +149,7 @@ func TestFSMWalkFromNewToFinished(t *testing.T) {
 	}
 
 	for _, want := range expectedStates {
-		require.NoError(t, wf.Next(), "transition to %s failed", want)
+		require.NoError(t, wf.Next(t.Context()), "transition to %s failed", want)
 		require.Equal(t, want, wf.Current(), "expected state %s after transition", want)
 	}
 }
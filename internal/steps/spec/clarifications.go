@@ -0,0 +1,122 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// clarificationsHeading is the optional appendix recording the clarifying
+// questions asked while the spec was written, when spec.append_qa is set.
+const clarificationsHeading = "## Clarifications"
+
+// Clarification is one clarifying question and the user's answer.
+type Clarification struct {
+	Header   string `json:"header,omitempty"`
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// ParseClarifications decodes the "clarifications" workflow data value, a
+// JSON array of {header, question, answer} objects. A nil value yields none.
+func ParseClarifications(v any) ([]Clarification, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding clarifications: %w", err)
+	}
+	var qas []Clarification
+	if err := json.Unmarshal(raw, &qas); err != nil {
+		return nil, fmt.Errorf("\"clarifications\" must be an array of {\"header\",\"question\",\"answer\"} objects: %w", err)
+	}
+	kept := qas[:0]
+	for _, qa := range qas {
+		if strings.TrimSpace(qa.Question) != "" {
+			kept = append(kept, qa)
+		}
+	}
+	return kept, nil
+}
+
+// UpsertClarifications writes qas into content as a "## Clarifications"
+// section. An existing section is replaced where it stands, so repeated runs
+// regenerate it rather than adding another; otherwise the section is appended.
+// With no clarifications content is returned unchanged.
+func UpsertClarifications(content string, qas []Clarification) string {
+	if len(qas) == 0 {
+		return content
+	}
+	section := renderClarifications(qas)
+
+	lines := strings.Split(content, "\n")
+	start, end := -1, len(lines)
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if start < 0 {
+			if m := headingRE.FindStringSubmatch(line); m != nil && len(m[1]) <= 2 && strings.EqualFold(m[2], "Clarifications") {
+				start = i
+			}
+			continue
+		}
+		if m := headingRE.FindStringSubmatch(line); m != nil && len(m[1]) <= 2 {
+			end = i
+			break
+		}
+	}
+
+	if start < 0 {
+		return strings.TrimRight(content, "\n") + "\n\n" + section
+	}
+	before := strings.TrimRight(strings.Join(lines[:start], "\n"), "\n")
+	after := strings.TrimLeft(strings.Join(lines[end:], "\n"), "\n")
+	if before != "" {
+		before += "\n\n"
+	}
+	if after != "" {
+		section += "\n" + after
+	}
+	return before + section
+}
+
+func renderClarifications(qas []Clarification) string {
+	var b strings.Builder
+	b.WriteString(clarificationsHeading + "\n\n")
+	for i, qa := range qas {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		question := strings.TrimSpace(qa.Question)
+		if header := strings.TrimSpace(qa.Header); header != "" {
+			question = "[" + header + "] " + question
+		}
+		answer := strings.TrimSpace(qa.Answer)
+		if answer == "" {
+			answer = "(no answer)"
+		}
+		fmt.Fprintf(&b, "- **Q:** %s\n  **A:** %s\n", question, indentContinuation(answer))
+	}
+	return b.String()
+}
+
+// indentContinuation indents every line after the first by two spaces so a
+// multi-line answer stays inside its list item. Blank lines stay empty.
+func indentContinuation(s string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = "  " + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
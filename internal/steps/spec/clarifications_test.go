@@ -0,0 +1,114 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/jumppad-labs/spektacular/internal/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var testClarifications = []Clarification{
+	{Header: "Storage", Question: "Which database?", Answer: "PostgreSQL"},
+	{Question: "Who are the users?", Answer: "Billing admins.\n\nAlso support staff."},
+}
+
+const testClarificationsSection = "## Clarifications\n\n" +
+	"- **Q:** [Storage] Which database?\n  **A:** PostgreSQL\n\n" +
+	"- **Q:** Who are the users?\n  **A:** Billing admins.\n\n  Also support staff.\n"
+
+func TestUpsertClarifications_AppendsSection(t *testing.T) {
+	got := UpsertClarifications("# Feature: x\n\n## Non-Goals\n\nNone.\n", testClarifications)
+	require.Equal(t, "# Feature: x\n\n## Non-Goals\n\nNone.\n\n"+testClarificationsSection, got)
+}
+
+func TestUpsertClarifications_IsIdempotent(t *testing.T) {
+	once := UpsertClarifications("# Feature: x\n\n## Overview\n\nText.\n", testClarifications)
+	require.Equal(t, once, UpsertClarifications(once, testClarifications))
+}
+
+func TestUpsertClarifications_ReplacesExistingSectionInPlace(t *testing.T) {
+	content := "# Feature: x\n\n## Clarifications\n\n- **Q:** Old?\n  **A:** Old.\n\n## Notes\n\nKeep me.\n"
+	got := UpsertClarifications(content, testClarifications[:1])
+	require.Equal(t, "# Feature: x\n\n## Clarifications\n\n- **Q:** [Storage] Which database?\n  **A:** PostgreSQL\n\n## Notes\n\nKeep me.\n", got)
+}
+
+func TestUpsertClarifications_NoneLeavesContentUnchanged(t *testing.T) {
+	require.Equal(t, "# Feature: x\n", UpsertClarifications("# Feature: x\n", nil))
+}
+
+func TestParseClarifications(t *testing.T) {
+	qas, err := ParseClarifications([]any{
+		map[string]any{"header": "Storage", "question": "Which database?", "answer": "PostgreSQL"},
+		map[string]any{"question": "  ", "answer": "dropped"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, testClarifications[:1], qas)
+
+	_, err = ParseClarifications("not a list")
+	require.ErrorContains(t, err, `"clarifications" must be an array`)
+}
+
+func TestNormalizeSpec_KnowsClarificationsSection(t *testing.T) {
+	got, _ := NormalizeSpec("# Feature: x\n\n### Clarifications\n\n- **Q:** a\n")
+	require.Equal(t, "# Feature: x\n\n## Clarifications\n\n- **Q:** a\n", got)
+}
+
+func finishWithClarifications(t *testing.T, st store.Store, qas []any, appendQA bool) *captureWriter {
+	t.Helper()
+	data := &testData{values: map[string]any{"name": "fixture", "clarifications": qas}}
+	writer := &captureWriter{}
+	_, err := finished()(data, writer, st, workflow.Config{Command: "spektacular", SpecDir: "specs", SpecAppendQA: appendQA})
+	require.NoError(t, err)
+	return writer
+}
+
+func TestFinishedStep_AppendsClarifications(t *testing.T) {
+	st := store.NewFileStore(t.TempDir(), "project")
+	path := SpecFilePath("specs", "fixture")
+	require.NoError(t, st.Write(path, []byte("# Feature: fixture\n\n## Overview\n\nDone.\n")))
+	qas := []any{map[string]any{"header": "Storage", "question": "Which database?", "answer": "PostgreSQL"}}
+
+	writer := finishWithClarifications(t, st, qas, true)
+	stored, err := st.Read(path)
+	require.NoError(t, err)
+	want := "# Feature: fixture\n\n## Overview\n\nDone.\n\n## Clarifications\n\n- **Q:** [Storage] Which database?\n  **A:** PostgreSQL\n"
+	require.Equal(t, want, string(stored))
+	require.Contains(t, writer.result.Instruction, "recorded the 1 clarifying question(s)")
+
+	// A later run with updated answers regenerates the section.
+	qas = []any{map[string]any{"header": "Storage", "question": "Which database?", "answer": "SQLite"}}
+	finishWithClarifications(t, st, qas, true)
+	stored, err = st.Read(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(stored), "## Clarifications"))
+	require.Contains(t, string(stored), "**A:** SQLite")
+	require.NotContains(t, string(stored), "PostgreSQL")
+}
+
+func TestFinishedStep_ClarificationsOffByDefault(t *testing.T) {
+	st := store.NewFileStore(t.TempDir(), "project")
+	path := SpecFilePath("specs", "fixture")
+	require.NoError(t, st.Write(path, []byte("# Feature: fixture\n\n## Overview\n\nDone.\n")))
+	qas := []any{map[string]any{"question": "Which database?", "answer": "PostgreSQL"}}
+
+	finishWithClarifications(t, st, qas, false)
+	stored, err := st.Read(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(stored), "Clarifications")
+}
+
+func TestVerificationStep_AsksForClarificationsWhenEnabled(t *testing.T) {
+	data := &testData{values: map[string]any{"name": "test"}}
+	writer := &captureWriter{}
+	st := store.NewFileStore(t.TempDir(), "project")
+
+	_, err := verification()(data, writer, st, workflow.Config{Command: "spektacular", SpecAppendQA: true})
+	require.NoError(t, err)
+	require.Contains(t, writer.result.Instruction, `"clarifications":[`)
+
+	_, err = verification()(data, writer, st, workflow.Config{Command: "spektacular"})
+	require.NoError(t, err)
+	require.NotContains(t, writer.result.Instruction, "clarifications")
+}
@@ -23,6 +23,17 @@ const (
 	MaxIdentifierPartLength = 64
 )
 
+// Policies for an explicit id that resolves to a spec which already exists.
+const (
+	OnExistsError     = "error"     // refuse to create the spec (default)
+	OnExistsVersion   = "version"   // create a numbered sibling, e.g. <name>-2
+	OnExistsOverwrite = "overwrite" // reuse the name; the caller archives the old spec first
+)
+
+// ErrSpecExists is returned when a spec with the resolved name already exists
+// and the request's OnExists policy is OnExistsError.
+var ErrSpecExists = errors.New("spec already exists")
+
 // IdentifierRequest describes the data needed to resolve a canonical spec name.
 type IdentifierRequest struct {
 	Name    string
@@ -31,11 +42,18 @@ type IdentifierRequest struct {
 	SpecDir string // configured spec directory; defaults to config.DefaultSpecDir when empty
 	Store   store.Store
 	Now     func() time.Time
+	// OnExists selects what happens when an explicit id resolves to an
+	// existing spec; one of the OnExists* constants, defaulting to
+	// OnExistsError.
+	OnExists string
 }
 
 // IdentifierResult is the canonical spec name.
 type IdentifierResult struct {
 	Name string
+	// Overwrite is set when Name refers to an existing spec that the caller
+	// must archive (see ArchiveSpec) before writing the new scaffold.
+	Overwrite bool
 }
 
 // ResolveIdentifier turns a requested spec name plus optional id into a
@@ -53,6 +71,9 @@ func ResolveIdentifier(req IdentifierRequest) (IdentifierResult, error) {
 	if err := validateMethod(method); err != nil {
 		return IdentifierResult{}, err
 	}
+	if err := validateOnExists(req.OnExists); err != nil {
+		return IdentifierResult{}, err
+	}
 
 	specDir := req.SpecDir
 	if specDir == "" {
@@ -64,11 +85,7 @@ func ResolveIdentifier(req IdentifierRequest) (IdentifierResult, error) {
 		if err != nil {
 			return IdentifierResult{}, err
 		}
-		resolved, err := resolveWithPrefix(req.Store, specDir, id, name)
-		if err != nil {
-			return IdentifierResult{}, err
-		}
-		return IdentifierResult{Name: resolved}, nil
+		return resolveWithPrefix(req.Store, specDir, id, name, req.OnExists)
 	}
 
 	switch method {
@@ -205,16 +222,63 @@ func nextCounterFromStore(st store.Store, specDir string) (int, error) {
 	return max + 1, nil
 }
 
-func resolveWithPrefix(st store.Store, specDir, prefix, name string) (string, error) {
+func resolveWithPrefix(st store.Store, specDir, prefix, name, onExists string) (IdentifierResult, error) {
 	resolved := fmt.Sprintf("%s-%s", prefix, name)
 	exists, err := specExists(st, specDir, resolved)
 	if err != nil {
-		return "", err
+		return IdentifierResult{}, err
 	}
-	if exists {
-		return "", fmt.Errorf("spec %q already exists", resolved)
+	if !exists {
+		return IdentifierResult{Name: resolved}, nil
+	}
+
+	switch onExists {
+	case OnExistsOverwrite:
+		return IdentifierResult{Name: resolved, Overwrite: true}, nil
+	case OnExistsVersion:
+		for n := 2; ; n++ {
+			versioned := fmt.Sprintf("%s-%d", resolved, n)
+			exists, err := specExists(st, specDir, versioned)
+			if err != nil {
+				return IdentifierResult{}, err
+			}
+			if !exists {
+				return IdentifierResult{Name: versioned}, nil
+			}
+		}
+	default:
+		return IdentifierResult{}, fmt.Errorf("%w: %q", ErrSpecExists, resolved)
+	}
+}
+
+// ArchiveSpec copies an existing spec to <specDir>/archive/<name>-<timestamp>.md
+// so it can be overwritten, and returns the archive's store-relative path. A
+// second archive within the same second gets a numbered suffix, e.g.
+// <name>-<timestamp>-2.md, rather than replacing the first.
+func ArchiveSpec(st store.Store, specDir, name string, now time.Time) (string, error) {
+	content, err := st.Read(SpecFilePath(specDir, name))
+	if err != nil {
+		return "", fmt.Errorf("reading spec %q to archive: %w", name, err)
+	}
+	archiveDir := specDir + "/archive"
+	archived := name + "-" + now.UTC().Format("20060102150405")
+	archivePath := SpecFilePath(archiveDir, archived)
+	for n := 2; st.Exists(archivePath); n++ {
+		archivePath = SpecFilePath(archiveDir, fmt.Sprintf("%s-%d", archived, n))
+	}
+	if err := st.Write(archivePath, content); err != nil {
+		return "", fmt.Errorf("archiving spec %q: %w", name, err)
+	}
+	return archivePath, nil
+}
+
+func validateOnExists(onExists string) error {
+	switch onExists {
+	case "", OnExistsError, OnExistsVersion, OnExistsOverwrite:
+		return nil
+	default:
+		return fmt.Errorf("unsupported on-exists policy %q (want %s, %s or %s)", onExists, OnExistsError, OnExistsVersion, OnExistsOverwrite)
 	}
-	return resolved, nil
 }
 
 func specExists(st store.Store, specDir, name string) (bool, error) {
@@ -179,6 +179,81 @@ func TestResolveIdentifier_ExplicitIDCollisionFails(t *testing.T) {
 	require.Contains(t, err.Error(), "already exists")
 }
 
+func TestResolveIdentifier_ExplicitIDCollisionPolicies(t *testing.T) {
+	tests := []struct {
+		name          string
+		onExists      string
+		existing      []string
+		wantName      string
+		wantOverwrite bool
+		wantErr       string
+	}{
+		{name: "default errors", existing: []string{"ext-123-billing"}, wantErr: "already exists"},
+		{name: "error", onExists: OnExistsError, existing: []string{"ext-123-billing"}, wantErr: "already exists"},
+		{name: "version", onExists: OnExistsVersion, existing: []string{"ext-123-billing"}, wantName: "ext-123-billing-2"},
+		{name: "version skips taken", onExists: OnExistsVersion, existing: []string{"ext-123-billing", "ext-123-billing-2"}, wantName: "ext-123-billing-3"},
+		{name: "overwrite", onExists: OnExistsOverwrite, existing: []string{"ext-123-billing"}, wantName: "ext-123-billing", wantOverwrite: true},
+		{name: "no collision ignores policy", onExists: OnExistsOverwrite, wantName: "ext-123-billing"},
+		{name: "unknown policy", onExists: "merge", wantErr: "unsupported on-exists policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := identifierStore(t)
+			for _, name := range tt.existing {
+				writeExistingSpec(t, st, name)
+			}
+
+			got, err := ResolveIdentifier(IdentifierRequest{
+				Name:     "billing",
+				ID:       "EXT.123",
+				Store:    st,
+				OnExists: tt.onExists,
+			})
+
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantName, got.Name)
+			require.Equal(t, tt.wantOverwrite, got.Overwrite)
+		})
+	}
+}
+
+func TestArchiveSpec_CopiesExistingSpec(t *testing.T) {
+	st := identifierStore(t)
+	writeExistingSpec(t, st, "ext-123-billing")
+
+	path, err := ArchiveSpec(st, config.DefaultSpecDir, "ext-123-billing", fixedIdentifierTime())
+	require.NoError(t, err)
+	require.Equal(t, config.DefaultSpecDir+"/archive/ext-123-billing-20260509010203.md", path)
+
+	archived, err := st.Read(path)
+	require.NoError(t, err)
+	require.Equal(t, "existing", string(archived))
+	require.True(t, st.Exists(SpecFilePath(config.DefaultSpecDir, "ext-123-billing")))
+}
+
+func TestArchiveSpec_SameSecondKeepsBothArchives(t *testing.T) {
+	st := identifierStore(t)
+	writeExistingSpec(t, st, "ext-123-billing")
+
+	first, err := ArchiveSpec(st, config.DefaultSpecDir, "ext-123-billing", fixedIdentifierTime())
+	require.NoError(t, err)
+	require.NoError(t, st.Write(SpecFilePath(config.DefaultSpecDir, "ext-123-billing"), []byte("rewritten")))
+	second, err := ArchiveSpec(st, config.DefaultSpecDir, "ext-123-billing", fixedIdentifierTime())
+	require.NoError(t, err)
+
+	require.Equal(t, config.DefaultSpecDir+"/archive/ext-123-billing-20260509010203-2.md", second)
+	archived, err := st.Read(first)
+	require.NoError(t, err)
+	require.Equal(t, "existing", string(archived))
+	archived, err = st.Read(second)
+	require.NoError(t, err)
+	require.Equal(t, "rewritten", string(archived))
+}
+
 func TestResolveIdentifier_ExternalRequiresID(t *testing.T) {
 	st := identifierStore(t)
 
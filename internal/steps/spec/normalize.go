@@ -0,0 +1,183 @@
+package spec
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sectionHeadings are the scaffold's top-level sections plus the optional
+// Clarifications appendix. Agents sometimes write them at the wrong heading
+// level; NormalizeSpec puts them back at "##".
+var sectionHeadings = []string{
+	"Overview",
+	"Requirements",
+	"Constraints",
+	"Acceptance Criteria",
+	"Technical Approach",
+	"Success Metrics",
+	"Non-Goals",
+	"Clarifications",
+}
+
+var (
+	headingRE     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	htmlCommentRE = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// NormalizeSpec tidies an agent-written spec: it strips leftover template
+// comments and trailing whitespace, puts the scaffold sections back at "##"
+// (leaving subsections that share a section's title alone), drops a section heading repeated back-to-back, collapses runs of blank
+// lines and ends the file with a single newline. Fenced code blocks are left
+// untouched. It returns the normalized content and a short description of
+// each kind of fix applied; no fixes means content was already clean.
+func NormalizeSpec(content string) (string, []string) {
+	var fixes []string
+	fix := func(msg string) {
+		for _, f := range fixes {
+			if f == msg {
+				return
+			}
+		}
+		fixes = append(fixes, msg)
+	}
+
+	if stripped := stripComments(content); stripped != content {
+		content = stripped
+		fix("removed leftover template comments")
+	}
+
+	var out []string
+	inFence := false
+	blanks := 0
+	lastHeading := ""
+	// sectionLevel is the source heading level of the section being read, or
+	// 0 outside one. A section title nested deeper than it, such as a
+	// "### Constraints" subsection under "## Requirements", is kept as is.
+	sectionLevel := 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			out = append(out, line)
+			blanks = 0
+			lastHeading = ""
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+
+		if trimmed := strings.TrimRight(line, " \t"); trimmed != line {
+			line = trimmed
+			fix("trimmed trailing whitespace")
+		}
+
+		if line == "" {
+			blanks++
+			if blanks > 1 {
+				fix("collapsed repeated blank lines")
+				continue
+			}
+			out = append(out, line)
+			continue
+		}
+		blanks = 0
+
+		if m := headingRE.FindStringSubmatch(line); m != nil {
+			level := len(m[1])
+			section, ok := sectionHeading(m[2])
+			if ok && sectionLevel > 0 && level > sectionLevel {
+				ok = false
+			}
+			if !ok && level <= sectionLevel {
+				sectionLevel = 0
+			}
+			if ok {
+				sectionLevel = level
+				if normalized := "## " + section; normalized != line {
+					line = normalized
+					fix("normalized section heading levels")
+				}
+				if line == lastHeading {
+					fix("removed duplicate section headings")
+					// Drop the blank line kept between the two headings too.
+					if len(out) > 0 && out[len(out)-1] == "" {
+						out = out[:len(out)-1]
+					}
+					continue
+				}
+				lastHeading = line
+				out = append(out, line)
+				continue
+			}
+		}
+		lastHeading = ""
+		out = append(out, line)
+	}
+
+	joined := strings.Join(out, "\n")
+	result := strings.TrimRight(joined, "\n") + "\n"
+	if result != joined {
+		fix("ended the file with a single newline")
+	}
+	return result, fixes
+}
+
+// stripComments removes HTML comments outside fenced code blocks, along with
+// any line left empty by the removal.
+func stripComments(content string) string {
+	var b strings.Builder
+	for i, block := range splitFences(content) {
+		if i%2 == 1 {
+			b.WriteString(block)
+			continue
+		}
+		b.WriteString(htmlCommentRE.ReplaceAllStringFunc(block, func(string) string { return "\x00" }))
+	}
+	lines := strings.Split(b.String(), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.Contains(line, "\x00") {
+			line = strings.ReplaceAll(line, "\x00", "")
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// splitFences splits content into alternating prose and fenced-code chunks;
+// odd indices are fenced code (including their fence lines).
+func splitFences(content string) []string {
+	var chunks []string
+	var cur strings.Builder
+	inFence := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		isFence := strings.HasPrefix(strings.TrimSpace(line), "```")
+		if isFence && !inFence {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+			inFence = true
+			cur.WriteString(line)
+			continue
+		}
+		cur.WriteString(line)
+		if isFence && inFence {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+			inFence = false
+		}
+	}
+	return append(chunks, cur.String())
+}
+
+func sectionHeading(title string) (string, bool) {
+	for _, s := range sectionHeadings {
+		if strings.EqualFold(title, s) {
+			return s, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,86 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/jumppad-labs/spektacular/internal/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSpec(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		wantFixes []string
+	}{
+		{
+			name:  "clean spec is unchanged",
+			input: "# Feature: x\n\n## Overview\n\nA thing.\n",
+			want:  "# Feature: x\n\n## Overview\n\nA thing.\n",
+		},
+		{
+			name:      "leftover template comments",
+			input:     "# Feature: x\n\n<!--\n  OVERVIEW\n  guidance\n-->\n## Overview\n\nA thing. <!-- inline -->\n",
+			want:      "# Feature: x\n\n## Overview\n\nA thing.\n",
+			wantFixes: []string{"removed leftover template comments", "trimmed trailing whitespace"},
+		},
+		{
+			name:      "heading levels and duplicates",
+			input:     "# Feature: x\n\n### overview\n\n## Overview\nA thing.\n\n# Non-Goals ##\n\n- none\n",
+			want:      "# Feature: x\n\n## Overview\nA thing.\n\n## Non-Goals\n\n- none\n",
+			wantFixes: []string{"normalized section heading levels", "removed duplicate section headings"},
+		},
+		{
+			name:  "nested subsections keep their level",
+			input: "# Feature: x\n\n## Requirements\n\n### Constraints\n\n- fast\n\n### Technical Approach\n\n- simple\n\n### Acceptance Criteria\n",
+			want:  "# Feature: x\n\n## Requirements\n\n### Constraints\n\n- fast\n\n### Technical Approach\n\n- simple\n\n### Acceptance Criteria\n",
+		},
+		{
+			name:      "sections written one level too deep",
+			input:     "# Feature: x\n\n### Requirements\n\n#### Constraints\n\n### Constraints\n",
+			want:      "# Feature: x\n\n## Requirements\n\n#### Constraints\n\n## Constraints\n",
+			wantFixes: []string{"normalized section heading levels"},
+		},
+		{
+			name:      "blank lines and trailing newline",
+			input:     "# Feature: x  \n\n\n\n## Overview\t\n\nA thing.",
+			want:      "# Feature: x\n\n## Overview\n\nA thing.\n",
+			wantFixes: []string{"trimmed trailing whitespace", "collapsed repeated blank lines", "ended the file with a single newline"},
+		},
+		{
+			name:  "fenced code is left alone",
+			input: "## Technical Approach\n\n```yaml\n# Overview\n<!-- keep -->\n\n\nkey: value   \n```\n",
+			want:  "## Technical Approach\n\n```yaml\n# Overview\n<!-- keep -->\n\n\nkey: value   \n```\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes := NormalizeSpec(tt.input)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantFixes, fixes)
+
+			again, fixes := NormalizeSpec(got)
+			require.Equal(t, got, again, "normalization must be idempotent")
+			require.Empty(t, fixes)
+		})
+	}
+}
+
+func TestFinishedStep_NormalizesCommittedSpec(t *testing.T) {
+	st := store.NewFileStore(t.TempDir(), "project")
+	path := SpecFilePath("specs", "fixture")
+	require.NoError(t, st.Write(path, []byte("# Feature: fixture\n\n### Overview\n\n\nDone.   \n")))
+
+	data := &testData{values: map[string]any{"name": "fixture"}}
+	writer := &captureWriter{}
+	_, err := finished()(data, writer, st, workflow.Config{Command: "spektacular", SpecDir: "specs"})
+	require.NoError(t, err)
+
+	stored, err := st.Read(path)
+	require.NoError(t, err)
+	require.Equal(t, "# Feature: fixture\n\n## Overview\n\nDone.\n", string(stored))
+	require.Contains(t, writer.result.Instruction, "Spektacular tidied the committed spec file")
+	require.Contains(t, writer.result.Instruction, "- normalized section heading levels")
+}
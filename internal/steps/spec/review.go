@@ -0,0 +1,67 @@
+package spec
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jumppad-labs/spektacular/internal/stepkit"
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/jumppad-labs/spektacular/internal/suggest"
+	"github.com/jumppad-labs/spektacular/internal/workflow"
+)
+
+// ReviewFilePath returns the store-relative path of a spec's review, written
+// beside the spec in the configured spec directory.
+func ReviewFilePath(dir, name string) string {
+	return dir + "/" + name + ".review.md"
+}
+
+// ReviewResult is returned by the review subcommand.
+type ReviewResult struct {
+	Step        string `json:"step"`
+	SpecPath    string `json:"spec_path"`
+	SpecName    string `json:"spec_name"`
+	ReviewPath  string `json:"review_path"`
+	Instruction string `json:"instruction"`
+}
+
+// ReviewSteps returns the single-step workflow that has the agent critique an
+// existing spec: ambiguities, untestable acceptance criteria and missing
+// non-goals are written to the review file, then offered to the user one at a
+// time to apply to the spec.
+func ReviewSteps() []workflow.StepConfig {
+	return []workflow.StepConfig{
+		{Name: "review", Src: []string{"start"}, Dst: "review", Callback: review()},
+	}
+}
+
+func review() workflow.StepCallback {
+	return func(data workflow.Data, out workflow.ResultWriter, st store.Store, cfg workflow.Config) (string, error) {
+		name := stepkit.GetString(data, "name")
+		if !st.Exists(SpecFilePath(cfg.SpecDir, name)) {
+			if hint := suggest.DidYouMean(suggest.Closest(name, SpecNames(st, cfg.SpecDir))); hint != "" {
+				return "", fmt.Errorf("spec %s not found — %s", SpecFilePath(cfg.SpecDir, name), hint)
+			}
+			return "", fmt.Errorf("spec %s not found", SpecFilePath(cfg.SpecDir, name))
+		}
+		reviewPath := filepath.Join(st.Root(), ReviewFilePath(cfg.SpecDir, name))
+		return "", stepkit.WriteStepResult(
+			stepkit.StepRequest{
+				StepName:     "review",
+				TemplatePath: "steps/spec/review.md",
+				Strategy:     strategy{specDir: cfg.SpecDir},
+				Extra:        map[string]any{"review_path": reviewPath},
+			},
+			data, out, st, cfg,
+			func(stepName, instanceName, primaryPath, instruction string) any {
+				return ReviewResult{
+					Step:        stepName,
+					SpecPath:    primaryPath,
+					SpecName:    instanceName,
+					ReviewPath:  reviewPath,
+					Instruction: instruction,
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,47 @@
+package spec
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/jumppad-labs/spektacular/internal/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+type reviewWriter struct {
+	result ReviewResult
+}
+
+func (w *reviewWriter) WriteResult(v any) error {
+	w.result = v.(ReviewResult)
+	return nil
+}
+
+func TestReviewStep_RendersCritique(t *testing.T) {
+	st := store.NewFileStore(t.TempDir(), "project")
+	require.NoError(t, st.Write(SpecFilePath("specs", "auth"), []byte("# Feature: auth\n")))
+	data := &testData{values: map[string]any{"name": "auth"}}
+	writer := &reviewWriter{}
+
+	_, err := review()(data, writer, st, workflow.Config{Command: "spektacular", SpecDir: "specs"})
+	require.NoError(t, err)
+
+	got := writer.result
+	require.Equal(t, "review", got.Step)
+	require.Equal(t, "auth", got.SpecName)
+	require.Equal(t, filepath.Join(st.Root(), "specs", "auth.md"), got.SpecPath)
+	require.Equal(t, filepath.Join(st.Root(), "specs", "auth.review.md"), got.ReviewPath)
+	require.Contains(t, got.Instruction, "Untestable acceptance criteria")
+	require.Contains(t, got.Instruction, "spektacular spec file write auth.review.md")
+	require.Contains(t, got.Instruction, `"Apply" and "Skip"`)
+	require.Contains(t, got.Instruction, "how many suggestions were applied")
+}
+
+func TestReviewStep_MissingSpec(t *testing.T) {
+	st := store.NewFileStore(t.TempDir(), "project")
+	data := &testData{values: map[string]any{"name": "nosuch"}}
+
+	_, err := review()(data, &reviewWriter{}, st, workflow.Config{SpecDir: "specs"})
+	require.ErrorContains(t, err, "spec specs/nosuch.md not found")
+}
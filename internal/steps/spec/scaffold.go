@@ -0,0 +1,107 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cbroglie/mustache"
+	"github.com/jumppad-labs/spektacular/templates"
+)
+
+// scaffoldTemplatePath is the embedded template a new spec file is created from.
+const scaffoldTemplatePath = "scaffold/spec.md"
+
+// scaffoldValues returns the replacement map applied to the spec scaffold.
+// Every placeholder in the scaffold template must have an entry here;
+// TemplatePlaceholders and the accompanying test keep the two in sync.
+func scaffoldValues(name string) map[string]any {
+	return map[string]any{"name": name}
+}
+
+// TemplatePlaceholders returns the sorted, de-duplicated names of every
+// mustache placeholder used by the spec scaffold template, including those
+// nested inside sections.
+func TemplatePlaceholders() ([]string, error) {
+	tmpl, err := loadScaffold()
+	if err != nil {
+		return nil, err
+	}
+	return placeholders(tmpl), nil
+}
+
+// ScaffoldWarnings reports the scaffold placeholders that have no known
+// replacement. Each one is rendered as a visible "TODO: <name>" marker in new
+// spec files rather than silently disappearing.
+func ScaffoldWarnings() ([]string, error) {
+	tmpl, err := loadScaffold()
+	if err != nil {
+		return nil, err
+	}
+	_, warnings := fillPlaceholders(tmpl, scaffoldValues(""))
+	return warnings, nil
+}
+
+// renderScaffold renders the spec scaffold for the named spec. Placeholders
+// without a known replacement are filled with a "TODO: <name>" marker.
+func renderScaffold(name string) (string, error) {
+	tmpl, err := loadScaffold()
+	if err != nil {
+		return "", err
+	}
+	values, _ := fillPlaceholders(tmpl, scaffoldValues(name))
+	return tmpl.Render(values)
+}
+
+func loadScaffold() (*mustache.Template, error) {
+	raw, err := templates.FS.ReadFile(scaffoldTemplatePath)
+	if err != nil {
+		return nil, fmt.Errorf("loading template %s: %w", scaffoldTemplatePath, err)
+	}
+	tmpl, err := mustache.ParseString(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", scaffoldTemplatePath, err)
+	}
+	return tmpl, nil
+}
+
+// fillPlaceholders copies known into a new map and adds a TODO marker for
+// every template placeholder missing from it, returning one warning per
+// placeholder it had to fill.
+func fillPlaceholders(tmpl *mustache.Template, known map[string]any) (map[string]any, []string) {
+	values := make(map[string]any, len(known))
+	for k, v := range known {
+		values[k] = v
+	}
+	var warnings []string
+	for _, name := range placeholders(tmpl) {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		values[name] = "TODO: " + name
+		warnings = append(warnings, fmt.Sprintf("spec template placeholder {{%s}} has no value; filled with \"TODO: %s\"", name, name))
+	}
+	return values, warnings
+}
+
+func placeholders(tmpl *mustache.Template) []string {
+	seen := map[string]bool{}
+	collectTags(tmpl.Tags(), seen)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func collectTags(tags []mustache.Tag, seen map[string]bool) {
+	for _, tag := range tags {
+		switch tag.Type() {
+		case mustache.Variable:
+			seen[tag.Name()] = true
+		case mustache.Section, mustache.InvertedSection:
+			seen[tag.Name()] = true
+			collectTags(tag.Tags(), seen)
+		}
+	}
+}
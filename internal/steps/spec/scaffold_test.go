@@ -0,0 +1,55 @@
+package spec
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cbroglie/mustache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatePlaceholders_MatchScaffoldValues(t *testing.T) {
+	placeholders, err := TemplatePlaceholders()
+	require.NoError(t, err)
+
+	known := make([]string, 0)
+	for k := range scaffoldValues("x") {
+		known = append(known, k)
+	}
+	sort.Strings(known)
+
+	require.Equal(t, known, placeholders, "scaffold template placeholders and scaffoldValues have drifted apart")
+
+	tmpl, err := loadScaffold()
+	require.NoError(t, err)
+	_, warnings := fillPlaceholders(tmpl, scaffoldValues("x"))
+	require.Empty(t, warnings, "the embedded scaffold has placeholders with no value")
+
+	warnings, err = ScaffoldWarnings()
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestFillPlaceholders_MarksUnknownAsTODO(t *testing.T) {
+	tmpl, err := mustache.ParseString("# {{name}}\n\nOwner: {{owner}}\n{{#extra}}{{ticket_id}}{{/extra}}\n")
+	require.NoError(t, err)
+
+	values, warnings := fillPlaceholders(tmpl, scaffoldValues("billing"))
+	require.Len(t, warnings, 3)
+	require.Contains(t, warnings[0], "{{extra}}")
+	require.Contains(t, warnings[1], "{{owner}}")
+	require.Contains(t, warnings[2], "{{ticket_id}}")
+
+	out, err := tmpl.Render(values)
+	require.NoError(t, err)
+	require.Contains(t, out, "# billing")
+	require.Contains(t, out, "Owner: TODO: owner")
+	require.Contains(t, out, "TODO: ticket_id")
+}
+
+func TestRenderScaffold_SubstitutesName(t *testing.T) {
+	out, err := renderScaffold("billing-export")
+	require.NoError(t, err)
+	require.Contains(t, out, "# Feature: billing-export")
+	require.NotContains(t, out, "TODO:")
+}
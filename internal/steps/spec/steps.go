@@ -2,6 +2,7 @@ package spec
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jumppad-labs/spektacular/internal/stepkit"
 	"github.com/jumppad-labs/spektacular/internal/store"
@@ -14,13 +15,31 @@ func SpecFilePath(dir, name string) string {
 	return dir + "/" + name + ".md"
 }
 
+// SpecNames returns the names of the specs in the configured spec
+// directory, skipping review files. A missing directory yields none.
+func SpecNames(st store.Store, dir string) []string {
+	entries, err := st.List(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name, ".md")
+		if e.IsDir || !ok || strings.HasSuffix(name, ".review") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 // Steps returns the ordered step configs for a spec workflow.
 // Each step has an explicit named callback — no string-based dispatch.
 // The first step "new" is internal: it creates the spec file and produces no
 // output, allowing the caller to automatically advance to "overview".
 func Steps() []workflow.StepConfig {
 	return []workflow.StepConfig{
-		{Name: "new", Src: []string{"start"}, Dst: "new", Callback: new()},
+		{Name: "new", Src: []string{"start"}, Dst: "new", Callback: new("overview")},
 		{Name: "overview", Src: []string{"new"}, Dst: "overview", Callback: overview()},
 		{Name: "requirements", Src: []string{"overview"}, Dst: "requirements", Callback: requirements()},
 		{Name: "acceptance_criteria", Src: []string{"requirements"}, Dst: "acceptance_criteria", Callback: acceptanceCriteria()},
@@ -33,6 +52,29 @@ func Steps() []workflow.StepConfig {
 	}
 }
 
+// ModeQuick is the workflow data value of "mode" for a quick spec.
+const ModeQuick = "quick"
+
+// QuickSteps returns the step configs for a quick spec: the scaffold is
+// created as usual, then a single "quick" step has the agent ask one
+// question and write every section itself before finishing.
+func QuickSteps() []workflow.StepConfig {
+	return []workflow.StepConfig{
+		{Name: "new", Src: []string{"start"}, Dst: "new", Callback: new("quick")},
+		{Name: "quick", Src: []string{"new"}, Dst: "quick", Callback: quick()},
+		{Name: "finished", Src: []string{"quick"}, Dst: "finished", Callback: finished()},
+	}
+}
+
+// StepsForMode returns the step configs for a spec workflow started in the
+// given mode, as recorded in the workflow's "mode" data.
+func StepsForMode(mode string) []workflow.StepConfig {
+	if mode == ModeQuick {
+		return QuickSteps()
+	}
+	return Steps()
+}
+
 // buildResult is the stepkit.ResultBuilder for the spec workflow.
 func buildResult(stepName, instanceName, primaryPath, instruction string) any {
 	return Result{
@@ -60,24 +102,24 @@ func writeStep(stepName, nextStep, templatePath string, data workflow.Data, out
 }
 
 // new creates the spec file and produces no output.
-// The caller is expected to immediately advance to "overview".
-func new() workflow.StepCallback {
+// The caller is expected to immediately advance to next.
+func new(next string) workflow.StepCallback {
 	return func(data workflow.Data, out workflow.ResultWriter, st store.Store, cfg workflow.Config) (string, error) {
 		if cfg.DryRun {
-			return "overview", nil
+			return next, nil
 		}
 		if st == nil {
 			return "", fmt.Errorf("store required for new step")
 		}
 		name := stepkit.GetString(data, "name")
-		rendered, err := stepkit.RenderTemplate("scaffold/spec.md", map[string]any{"name": name})
+		rendered, err := renderScaffold(name)
 		if err != nil {
 			return "", err
 		}
 		if err := st.Write(SpecFilePath(cfg.SpecDir, name), []byte(rendered)); err != nil {
 			return "", err
 		}
-		return "overview", nil
+		return next, nil
 	}
 }
 
@@ -126,12 +168,26 @@ func nonGoals() workflow.StepCallback {
 func verification() workflow.StepCallback {
 	return func(data workflow.Data, out workflow.ResultWriter, st store.Store, cfg workflow.Config) (string, error) {
 		specName := stepkit.GetString(data, "name")
-		scaffold, err := stepkit.RenderTemplate("scaffold/spec.md", map[string]any{"name": specName})
+		scaffold, err := renderScaffold(specName)
 		if err != nil {
 			return "", err
 		}
 		return "", writeStep("verification", "finished", "steps/spec/08-verification.md", data, out, st, cfg, map[string]any{
-			"spec_template": scaffold,
+			"spec_template":  scaffold,
+			"spec_append_qa": cfg.SpecAppendQA,
+		})
+	}
+}
+
+func quick() workflow.StepCallback {
+	return func(data workflow.Data, out workflow.ResultWriter, st store.Store, cfg workflow.Config) (string, error) {
+		scaffold, err := renderScaffold(stepkit.GetString(data, "name"))
+		if err != nil {
+			return "", err
+		}
+		return "", writeStep("quick", "finished", "steps/spec/quick.md", data, out, st, cfg, map[string]any{
+			"spec_template":  scaffold,
+			"spec_append_qa": cfg.SpecAppendQA,
 		})
 	}
 }
@@ -142,14 +198,35 @@ func finished() workflow.StepCallback {
 		// verification step via `spec file write`. Read it back through the
 		// store; if it is still the empty scaffold the agent skipped that
 		// write, so surface a warning in the finished instruction.
-		var extra map[string]any
+		extra := map[string]any{}
+		if stepkit.GetString(data, "mode") == ModeQuick {
+			extra["spec_quick"] = true
+		}
 		if !cfg.DryRun && st != nil {
 			unwritten, err := specStillScaffold(st, cfg, stepkit.GetString(data, "name"))
 			if err != nil {
 				return "", err
 			}
 			if unwritten {
-				extra = map[string]any{"spec_unwritten": true}
+				extra["spec_unwritten"] = true
+			} else {
+				if cfg.SpecAppendQA {
+					n, err := appendStoredClarifications(st, cfg, data)
+					if err != nil {
+						return "", err
+					}
+					if n > 0 {
+						extra["spec_clarifications"] = n
+					}
+				}
+				fixes, err := normalizeStoredSpec(st, cfg, stepkit.GetString(data, "name"))
+				if err != nil {
+					return "", err
+				}
+				if len(fixes) > 0 {
+					extra["spec_normalized"] = true
+					extra["spec_fixes"] = fixes
+				}
 			}
 		}
 		return "", writeStep("finished", "", "steps/spec/09-finished.md", data, out, st, cfg, extra)
@@ -165,9 +242,49 @@ func specStillScaffold(st store.Store, cfg workflow.Config, specName string) (bo
 	if err != nil {
 		return true, nil
 	}
-	scaffold, err := stepkit.RenderTemplate("scaffold/spec.md", map[string]any{"name": specName})
+	scaffold, err := renderScaffold(specName)
 	if err != nil {
 		return false, err
 	}
 	return string(stored) == scaffold, nil
 }
+
+// appendStoredClarifications writes the "clarifications" workflow data into
+// the stored spec's Clarifications section and returns how many were recorded.
+func appendStoredClarifications(st store.Store, cfg workflow.Config, data workflow.Data) (int, error) {
+	raw, _ := data.Get("clarifications")
+	qas, err := ParseClarifications(raw)
+	if err != nil || len(qas) == 0 {
+		return 0, err
+	}
+	path := SpecFilePath(cfg.SpecDir, stepkit.GetString(data, "name"))
+	stored, err := st.Read(path)
+	if err != nil {
+		return 0, err
+	}
+	updated := UpsertClarifications(string(stored), qas)
+	if updated != string(stored) {
+		if err := st.Write(path, []byte(updated)); err != nil {
+			return 0, fmt.Errorf("writing spec clarifications: %w", err)
+		}
+	}
+	return len(qas), nil
+}
+
+// normalizeStoredSpec runs NormalizeSpec over the committed spec and writes it
+// back only when something changed, returning the fixes applied.
+func normalizeStoredSpec(st store.Store, cfg workflow.Config, specName string) ([]string, error) {
+	path := SpecFilePath(cfg.SpecDir, specName)
+	stored, err := st.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	normalized, fixes := NormalizeSpec(string(stored))
+	if normalized == string(stored) {
+		return nil, nil
+	}
+	if err := st.Write(path, []byte(normalized)); err != nil {
+		return nil, fmt.Errorf("writing normalized spec: %w", err)
+	}
+	return fixes, nil
+}
@@ -86,7 +86,7 @@ func TestFSMWalkFromNewToFinished(t *testing.T) {
 	}
 
 	for _, want := range expectedStates {
-		require.NoError(t, wf.Next(), "transition to %s failed", want)
+		require.NoError(t, wf.Next(t.Context()), "transition to %s failed", want)
 		require.Equal(t, want, wf.Current(), "expected state %s after transition", want)
 	}
 }
@@ -115,7 +115,7 @@ func TestNewStepWritesScaffold(t *testing.T) {
 	writer := &captureWriter{}
 	st := store.NewFileStore(tmp, "project")
 
-	next, err := new()(data, writer, st, workflow.Config{Command: "spektacular", SpecDir: "specs"})
+	next, err := new("overview")(data, writer, st, workflow.Config{Command: "spektacular", SpecDir: "specs"})
 	require.NoError(t, err)
 	require.Equal(t, "overview", next)
 	require.True(t, st.Exists(SpecFilePath("specs", "fixture")))
@@ -136,8 +136,27 @@ func TestNewStep_WritesUnderConfiguredSpecDir(t *testing.T) {
 	writer := &captureWriter{}
 	st := store.NewFileStore(tmp, "project")
 
-	_, err := new()(data, writer, st, workflow.Config{Command: "spektacular", SpecDir: "my-specs"})
+	_, err := new("overview")(data, writer, st, workflow.Config{Command: "spektacular", SpecDir: "my-specs"})
 	require.NoError(t, err)
 	require.True(t, st.Exists(SpecFilePath("my-specs", "fixture")), "spec must land under my-specs")
 	require.False(t, st.Exists(SpecFilePath("specs", "fixture")), "spec must not land under default specs")
 }
+
+func TestQuickStepsWalkFromNewToFinished(t *testing.T) {
+	tmp := t.TempDir()
+	st := store.NewFileStore(tmp, "project")
+	writer := &captureWriter{}
+
+	wf := workflow.New(QuickSteps(), filepath.Join(tmp, "state.json"), workflow.Config{Command: "spektacular", DryRun: true}, st, writer)
+	wf.SetData("name", "test")
+	wf.SetData("mode", ModeQuick)
+
+	require.NoError(t, wf.Next(t.Context()))
+	require.Equal(t, "quick", wf.Current())
+	require.Equal(t, "finished", wf.NextStepName())
+	require.Contains(t, writer.result.Instruction, "(inferred — review)")
+
+	require.NoError(t, wf.Goto(t.Context(), "finished"))
+	require.Equal(t, "finished", wf.Current())
+	require.Contains(t, writer.result.Instruction, "This was a quick spec")
+}
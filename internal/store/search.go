@@ -3,6 +3,7 @@ package store
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -27,17 +28,20 @@ const scanBufferBytes = 1024 * 1024
 // walk. Both paths perform a literal, case-insensitive substring match and
 // produce equivalent scope-tagged hits, so no caller can observe which ran.
 // An empty query, or a query with no matches, returns an empty result, not an
-// error.
-func (f *FileStore) Search(query string) ([]Hit, error) {
+// error. Cancelling ctx kills rg or stops the walk between files.
+func (f *FileStore) Search(ctx context.Context, query string) ([]Hit, error) {
 	if query == "" {
 		return nil, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if !f.forceFallback {
 		if rgPath, err := exec.LookPath("rg"); err == nil {
-			return f.searchRipgrep(rgPath, query)
+			return f.searchRipgrep(ctx, rgPath, query)
 		}
 	}
-	return f.searchNative(query)
+	return f.searchNative(ctx, query)
 }
 
 // rgEvent is the subset of a ripgrep --json event this package decodes. Only
@@ -62,9 +66,12 @@ type rgEvent struct {
 // searchRipgrep runs `rg` over the store root and decodes its JSON event
 // stream into hits. --fixed-strings and --ignore-case make rg's matching
 // literal and case-insensitive, matching the native fallback exactly.
-func (f *FileStore) searchRipgrep(rgPath, query string) ([]Hit, error) {
-	cmd := exec.Command(rgPath, "--json", "--no-heading", "--fixed-strings", "--ignore-case", query, f.root)
+func (f *FileStore) searchRipgrep(ctx context.Context, rgPath, query string) ([]Hit, error) {
+	cmd := exec.CommandContext(ctx, rgPath, "--json", "--no-heading", "--fixed-strings", "--ignore-case", query, f.root)
 	out, err := cmd.Output()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		// rg exits 1 when there are simply no matches — not an error.
 		var exitErr *exec.ExitError
@@ -105,7 +112,7 @@ func (f *FileStore) searchRipgrep(rgPath, query string) ([]Hit, error) {
 
 // searchNative walks the store root and scans every file line by line for a
 // case-insensitive substring match. It is the fallback when rg is unavailable.
-func (f *FileStore) searchNative(query string) ([]Hit, error) {
+func (f *FileStore) searchNative(ctx context.Context, query string) ([]Hit, error) {
 	needle := strings.ToLower(query)
 	var hits []Hit
 
@@ -113,6 +120,9 @@ func (f *FileStore) searchNative(query string) ([]Hit, error) {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if d.IsDir() {
 			return nil
 		}
@@ -133,6 +143,9 @@ func (f *FileStore) searchNative(query string) ([]Hit, error) {
 		}
 		return nil
 	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("native search: %w", err)
 	}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"os/exec"
 	"strings"
 	"testing"
@@ -55,7 +56,7 @@ func TestSearch_ExcerptWithinBudget(t *testing.T) {
 	st := NewFileStore(dir, "project")
 	st.forceFallback = true
 
-	hits, err := st.Search("needle")
+	hits, err := st.Search(t.Context(), "needle")
 	require.NoError(t, err)
 	require.NotEmpty(t, hits, "fixture should yield matches for 'needle'")
 
@@ -87,9 +88,9 @@ func TestSearch_RipgrepAndFallbackEquivalent(t *testing.T) {
 	fbStore := NewFileStore(dir, "project")
 	fbStore.forceFallback = true
 
-	rgHits, err := rgStore.Search("needle")
+	rgHits, err := rgStore.Search(t.Context(), "needle")
 	require.NoError(t, err)
-	fbHits, err := fbStore.Search("needle")
+	fbHits, err := fbStore.Search(t.Context(), "needle")
 	require.NoError(t, err)
 
 	require.NotEmpty(t, rgHits, "rg path should find matches")
@@ -103,7 +104,7 @@ func TestSearch_ScopeAndLocatorRoundTrip(t *testing.T) {
 	st := NewFileStore(dir, "project")
 	st.forceFallback = true
 
-	hits, err := st.Search("needle")
+	hits, err := st.Search(t.Context(), "needle")
 	require.NoError(t, err)
 	require.NotEmpty(t, hits)
 
@@ -114,7 +115,7 @@ func TestSearch_ScopeAndLocatorRoundTrip(t *testing.T) {
 		require.NotEmpty(t, data)
 	}
 
-	noHits, err := st.Search("zzz-does-not-exist-zzz")
+	noHits, err := st.Search(t.Context(), "zzz-does-not-exist-zzz")
 	require.NoError(t, err)
 	require.Empty(t, noHits)
 }
@@ -129,11 +130,25 @@ func TestSearch_RipgrepEmptyResults(t *testing.T) {
 	dir := writeSearchFixture(t)
 	st := NewFileStore(dir, "project")
 
-	noHits, err := st.Search("zzz-does-not-exist-zzz")
+	noHits, err := st.Search(t.Context(), "zzz-does-not-exist-zzz")
 	require.NoError(t, err)
 	require.Empty(t, noHits)
 
-	emptyHits, err := st.Search("")
+	emptyHits, err := st.Search(t.Context(), "")
 	require.NoError(t, err)
 	require.Empty(t, emptyHits)
 }
+
+func TestSearch_CancelledContext(t *testing.T) {
+	dir := writeSearchFixture(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	for _, fallback := range []bool{true, false} {
+		st := NewFileStore(dir, "project")
+		st.forceFallback = fallback
+		hits, err := st.Search(ctx, "needle")
+		require.ErrorIs(t, err, context.Canceled)
+		require.Empty(t, hits)
+	}
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"errors"
 	"io/fs"
 	"os"
@@ -46,7 +47,8 @@ type Store interface {
 	Exists(path string) bool
 	// Search returns hits for a free-form keyword query, scanning only this
 	// store. Hits carry the store's own scope so callers can attribute them.
-	Search(query string) ([]Hit, error)
+	// A cancelled ctx abandons the scan and returns its error.
+	Search(ctx context.Context, query string) ([]Hit, error)
 }
 
 // FileStore implements Store over the local filesystem.
@@ -135,11 +137,22 @@ func (f *FileStore) List(path string) ([]DirEntry, error) {
 	}
 	result := make([]DirEntry, len(entries))
 	for i, e := range entries {
-		result[i] = DirEntry{Name: e.Name(), IsDir: e.IsDir()}
+		result[i] = DirEntry{Name: e.Name(), IsDir: isDir(filepath.Join(abs, e.Name()), e)}
 	}
 	return result, nil
 }
 
+// isDir reports whether e is a directory, following a symlink to its target
+// so directories linked in from elsewhere (a shared docs repo, say) list like
+// any other. A dangling link is reported as a file.
+func isDir(path string, e fs.DirEntry) bool {
+	if e.Type()&fs.ModeSymlink == 0 {
+		return e.IsDir()
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 func (f *FileStore) Exists(path string) bool {
 	abs, err := f.abs(path)
 	if err != nil {
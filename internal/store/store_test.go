@@ -2,6 +2,7 @@ package store
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -64,6 +65,31 @@ func TestList_DistinguishesFilesFromDirectories(t *testing.T) {
 	}, entries)
 }
 
+func TestList_FollowsSymlinks(t *testing.T) {
+	st := newTestStore(t)
+	shared := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(shared, "billing"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "spec.md"), []byte("shared spec"), 0o644))
+
+	require.NoError(t, st.Write("specs/local.md", []byte("local")))
+	require.NoError(t, os.Symlink(filepath.Join(shared, "spec.md"), filepath.Join(st.Root(), "specs", "linked.md")))
+	require.NoError(t, os.Symlink(filepath.Join(shared, "billing"), filepath.Join(st.Root(), "specs", "billing")))
+	require.NoError(t, os.Symlink(filepath.Join(shared, "gone"), filepath.Join(st.Root(), "specs", "dangling")))
+
+	entries, err := st.List("specs")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []DirEntry{
+		{Name: "local.md", IsDir: false},
+		{Name: "linked.md", IsDir: false},
+		{Name: "billing", IsDir: true},
+		{Name: "dangling", IsDir: false},
+	}, entries)
+
+	data, err := st.Read("specs/linked.md")
+	require.NoError(t, err)
+	require.Equal(t, "shared spec", string(data))
+}
+
 func TestList_ReturnsErrNotFoundForMissingDir(t *testing.T) {
 	st := newTestStore(t)
 	_, err := st.List("nodir")
@@ -0,0 +1,77 @@
+// Package suggest offers "did you mean" hints for names that failed to
+// resolve, such as a misspelled spec or plan name.
+package suggest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Closest returns the candidates nearest to target by edit distance. Ties
+// are all returned, sorted alphabetically. Nothing is returned when no
+// candidate is plausibly a misspelling of target — i.e. when the best
+// distance exceeds a third of the target's length (minimum 1).
+func Closest(target string, candidates []string) []string {
+	limit := len(target) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	best := limit + 1
+	var matches []string
+	for _, c := range candidates {
+		if c == target {
+			continue
+		}
+		d := distance(target, c)
+		switch {
+		case d < best:
+			best = d
+			matches = []string{c}
+		case d == best:
+			matches = append(matches, c)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// DidYouMean formats matches as a hint suffix, e.g. "did you mean my-feature?"
+// or "did you mean a or b?". It returns "" when matches is empty.
+func DidYouMean(matches []string) string {
+	switch len(matches) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("did you mean %s?", matches[0])
+	default:
+		return fmt.Sprintf("did you mean %s or %s?", strings.Join(matches[:len(matches)-1], ", "), matches[len(matches)-1])
+	}
+}
+
+// distance is the Levenshtein edit distance between a and b, counting a
+// transposition of adjacent characters as a single edit.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
@@ -0,0 +1,34 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"my-feature", "billing-export", "auth-flow", "auth-flaw"}
+
+	tests := []struct {
+		name   string
+		target string
+		want   []string
+	}{
+		{name: "transposition", target: "my-featrue", want: []string{"my-feature"}},
+		{name: "missing letter", target: "biling-export", want: []string{"billing-export"}},
+		{name: "tie", target: "auth-flew", want: []string{"auth-flaw", "auth-flow"}},
+		{name: "no plausible match", target: "payments", want: nil},
+		{name: "exact match is not a suggestion", target: "my-feature", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Closest(tt.target, candidates))
+		})
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	require.Equal(t, "", DidYouMean(nil))
+	require.Equal(t, "did you mean my-feature?", DidYouMean([]string{"my-feature"}))
+	require.Equal(t, "did you mean a, b or c?", DidYouMean([]string{"a", "b", "c"}))
+}
@@ -0,0 +1,142 @@
+// Package trust records which project configurations the user has approved.
+// A project config can set values that decide what runs on the user's behalf,
+// such as the command the agent is told to invoke. Workflows refuse to run
+// under such a config until the user has reviewed and trusted it.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+)
+
+// EnvBypass is the environment variable that, set to "1" or "true", skips
+// the trust check, for CI where no one can confirm.
+const EnvBypass = "SPEKTACULAR_TRUST"
+
+// Field is a sensitive config value shown to the user for review.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// SensitiveFields returns the values in cfg that change what is executed on
+// the user's behalf: a command other than the default, which workflow
+// instructions tell the agent to run, and any agent_env additions or
+// removals. An empty result means the config needs no trust.
+func SensitiveFields(cfg config.Config) []Field {
+	var fields []Field
+	if cfg.Command != "" && cfg.Command != config.NewDefault().Command {
+		fields = append(fields, Field{Name: "command", Value: cfg.Command})
+	}
+	names := make([]string, 0, len(cfg.AgentEnv.Set))
+	for name := range cfg.AgentEnv.Set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, Field{Name: "agent_env.set." + name, Value: cfg.AgentEnv.Set[name]})
+	}
+	if len(cfg.AgentEnv.Remove) > 0 {
+		fields = append(fields, Field{Name: "agent_env.remove", Value: strings.Join(cfg.AgentEnv.Remove, ", ")})
+	}
+	return fields
+}
+
+// Fingerprint hashes the sensitive fields, so a trusted config is asked
+// about again only when one of them changes.
+func Fingerprint(fields []Field) string {
+	h := sha256.New()
+	for _, f := range fields {
+		fmt.Fprintf(h, "%s\x00%s\x00", f.Name, f.Value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Bypassed reports whether EnvBypass is set to skip the trust check.
+func Bypassed() bool {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(EnvBypass)))
+	return v == "1" || v == "true"
+}
+
+// Store is the user-level record of trusted project configs, kept outside
+// any project so a cloned repository cannot mark itself trusted.
+type Store struct {
+	path string
+}
+
+// NewStore returns a Store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultStore returns the Store in the user's config directory
+// (e.g. ~/.config/spektacular/trusted.json).
+func DefaultStore() (*Store, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("locating user config directory: %w", err)
+	}
+	return NewStore(filepath.Join(dir, "spektacular", "trusted.json")), nil
+}
+
+// trustFile maps absolute project roots to the fingerprint the user trusted.
+type trustFile struct {
+	Projects map[string]string `json:"projects"`
+}
+
+// Trusted reports whether the project at root was trusted with fingerprint.
+func (s *Store) Trusted(root, fingerprint string) (bool, error) {
+	tf, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	return tf.Projects[root] == fingerprint, nil
+}
+
+// Trust records fingerprint as trusted for the project at root.
+func (s *Store) Trust(root, fingerprint string) error {
+	tf, err := s.load()
+	if err != nil {
+		return err
+	}
+	tf.Projects[root] = fingerprint
+	data, err := json.MarshalIndent(tf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding trust file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("creating trust file directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing trust file: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) load() (trustFile, error) {
+	tf := trustFile{Projects: map[string]string{}}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tf, nil
+	}
+	if err != nil {
+		return tf, fmt.Errorf("reading trust file: %w", err)
+	}
+	if err := json.Unmarshal(data, &tf); err != nil {
+		return tf, fmt.Errorf("parsing trust file %s: %w", s.path, err)
+	}
+	if tf.Projects == nil {
+		tf.Projects = map[string]string{}
+	}
+	return tf, nil
+}
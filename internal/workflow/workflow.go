@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"time"
@@ -18,8 +19,14 @@ type Config struct {
 	// workflows write into, sourced from the project configuration.
 	SpecDir string
 	PlanDir string
+	// SpecAppendQA records the clarifying Q&A in the finished spec
+	// (spec.append_qa).
+	SpecAppendQA bool
 }
 
+// ErrNoSteps is returned when a workflow built with no steps is advanced.
+var ErrNoSteps = errors.New("workflow has no steps")
+
 // ResultWriter is implemented by the output writer and passed into step callbacks.
 type ResultWriter interface {
 	WriteResult(v any) error
@@ -57,7 +64,9 @@ type Workflow struct {
 
 // New builds a workflow FSM from step configs.
 // If a state file exists at statePath, its CurrentStep is used as the initial
-// FSM state. Otherwise the initial state defaults to steps[0].Src[0].
+// FSM state. Otherwise the initial state defaults to steps[0].Src[0], or
+// "start" for a workflow with no steps, which Next and Goto reject with
+// ErrNoSteps.
 // An implicit "done" transition is appended after the last step.
 // State is automatically persisted on every transition unless cfg.DryRun is true.
 // st is the project store passed to every step callback; it may be nil for
@@ -73,7 +82,10 @@ func New(steps []StepConfig, statePath string, cfg Config, st store.Store, out R
 		state = s
 		initialState = s.CurrentStep
 	} else {
-		initialState = steps[0].Src[0]
+		initialState = "start"
+		if len(steps) > 0 && len(steps[0].Src) > 0 {
+			initialState = steps[0].Src[0]
+		}
 		now := time.Now().UTC()
 		state = &State{
 			CurrentStep:    initialState,
@@ -144,18 +156,25 @@ func New(steps []StepConfig, statePath string, cfg Config, st store.Store, out R
 
 // Next fires the first available transition.
 // If the step callback returns a next step name, Next delegates to Goto to
-// advance the workflow further.
-func (w *Workflow) Next() error {
+// advance the workflow further. A cancelled ctx stops the workflow before
+// the next transition.
+func (w *Workflow) Next(ctx context.Context) error {
+	if len(w.steps) == 0 {
+		return ErrNoSteps
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	transitions := w.FSM.AvailableTransitions()
 	if len(transitions) == 0 {
 		return fmt.Errorf("workflow is already complete")
 	}
 	w.pendingGoto = ""
-	if err := w.FSM.Event(context.Background(), transitions[0]); err != nil {
+	if err := w.FSM.Event(ctx, transitions[0]); err != nil {
 		return err
 	}
 	if w.pendingGoto != "" {
-		return w.Goto(w.pendingGoto)
+		return w.Goto(ctx, w.pendingGoto)
 	}
 	w.commitTerminal()
 	return nil
@@ -164,17 +183,24 @@ func (w *Workflow) Next() error {
 // Goto jumps to a named step by firing the corresponding FSM event.
 // The step's Src list must include the current state; otherwise the FSM errors.
 // If the step callback returns a next step name, Goto calls itself recursively.
-func (w *Workflow) Goto(name string) error {
+// A cancelled ctx stops the workflow before the next transition.
+func (w *Workflow) Goto(ctx context.Context, name string) error {
+	if len(w.steps) == 0 {
+		return ErrNoSteps
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if w.Current() == name {
 		return nil
 	}
 
 	w.pendingGoto = ""
-	if err := w.FSM.Event(context.Background(), name); err != nil {
+	if err := w.FSM.Event(ctx, name); err != nil {
 		return err
 	}
 	if w.pendingGoto != "" {
-		return w.Goto(w.pendingGoto)
+		return w.Goto(ctx, w.pendingGoto)
 	}
 	w.commitTerminal()
 	return nil
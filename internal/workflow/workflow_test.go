@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
@@ -26,19 +27,19 @@ func TestNextAdvancesThroughAllSteps(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	err := wf.Next() // new → one
+	err := wf.Next(t.Context()) // new → one
 	require.NoError(t, err)
 	require.Equal(t, "one", wf.Current())
 
-	err = wf.Next() // one → two
+	err = wf.Next(t.Context()) // one → two
 	require.NoError(t, err)
 	require.Equal(t, "two", wf.Current())
 
-	err = wf.Next() // two → three
+	err = wf.Next(t.Context()) // two → three
 	require.NoError(t, err)
 	require.Equal(t, "three", wf.Current())
 
-	err = wf.Next() // three → done
+	err = wf.Next(t.Context()) // three → done
 	require.NoError(t, err)
 	require.True(t, wf.IsComplete())
 }
@@ -48,21 +49,50 @@ func TestNextOnCompleteErrors(t *testing.T) {
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
 	for i := 0; i <= len(testSteps); i++ {
-		err := wf.Next()
+		err := wf.Next(t.Context())
 		require.NoError(t, err)
 	}
 
-	err := wf.Next()
+	err := wf.Next(t.Context())
 	require.Error(t, err)
 }
 
+func TestEmptyWorkflowRejectsTransitions(t *testing.T) {
+	sp := filepath.Join(t.TempDir(), "state.json")
+	wf := New(nil, sp, Config{}, nil, nil)
+
+	require.Equal(t, "start", wf.Current())
+	require.ErrorIs(t, wf.Next(t.Context()), ErrNoSteps)
+	require.ErrorIs(t, wf.Goto(t.Context(), "anything"), ErrNoSteps)
+	require.Empty(t, wf.StepNames())
+	require.NoFileExists(t, sp)
+}
+
+func TestNextStopsWhenContextCancelled(t *testing.T) {
+	sp := filepath.Join(t.TempDir(), "state.json")
+	ctx, cancel := context.WithCancel(t.Context())
+	steps := []StepConfig{
+		{Name: "one", Src: []string{"new"}, Dst: "one", Callback: func(Data, ResultWriter, store.Store, Config) (string, error) {
+			cancel() // e.g. the user interrupts while the step runs
+			return "two", nil
+		}},
+		{Name: "two", Src: []string{"one"}, Dst: "two"},
+	}
+	wf := New(steps, sp, Config{}, nil, nil)
+
+	require.ErrorIs(t, wf.Next(ctx), context.Canceled)
+	require.Equal(t, "one", wf.Current(), "the chained goto must not run after cancellation")
+	require.ErrorIs(t, wf.Next(ctx), context.Canceled)
+	require.Equal(t, "one", wf.Current())
+}
+
 func TestGotoForward(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	wf.Next() // → one
+	wf.Next(t.Context()) // → one
 
-	err := wf.Goto("two")
+	err := wf.Goto(t.Context(), "two")
 	require.NoError(t, err)
 	require.Equal(t, "two", wf.Current())
 }
@@ -71,9 +101,9 @@ func TestGotoSameStepIsNoop(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	wf.Next() // → one
+	wf.Next(t.Context()) // → one
 
-	err := wf.Goto("one")
+	err := wf.Goto(t.Context(), "one")
 	require.NoError(t, err)
 	require.Equal(t, "one", wf.Current())
 }
@@ -82,7 +112,7 @@ func TestGotoInvalidStepFails(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	err := wf.Goto("nonexistent")
+	err := wf.Goto(t.Context(), "nonexistent")
 	require.Error(t, err)
 }
 
@@ -90,8 +120,8 @@ func TestAutoSaveOnTransition(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	wf.Next() // → one
-	wf.Next() // → two
+	wf.Next(t.Context()) // → one
+	wf.Next(t.Context()) // → two
 
 	// Rebuild from persisted state (auto-saved by enter_state).
 	loaded := New(testSteps, sp, Config{}, nil, nil)
@@ -103,8 +133,8 @@ func TestStepStatus(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	wf.Next() // → one
-	wf.Next() // → two
+	wf.Next(t.Context()) // → one
+	wf.Next(t.Context()) // → two
 
 	infos := wf.StepStatus()
 	require.Len(t, infos, 3)
@@ -117,11 +147,11 @@ func TestGotoBackwardFails(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	wf.Next() // → one
-	wf.Next() // → two
-	wf.Next() // → three
+	wf.Next(t.Context()) // → one
+	wf.Next(t.Context()) // → two
+	wf.Next(t.Context()) // → three
 
-	err := wf.Goto("one")
+	err := wf.Goto(t.Context(), "one")
 	require.Error(t, err)
 }
 
@@ -129,13 +159,13 @@ func TestNextStepName(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	wf.Next() // → one
+	wf.Next(t.Context()) // → one
 	require.Equal(t, "two", wf.NextStepName())
 
-	wf.Next() // → two
+	wf.Next(t.Context()) // → two
 	require.Equal(t, "three", wf.NextStepName())
 
-	wf.Next() // → three
+	wf.Next(t.Context()) // → three
 	require.Equal(t, "", wf.NextStepName())
 }
 
@@ -155,7 +185,7 @@ func TestCallbackGotoAdvances(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(steps, sp, Config{}, nil, nil)
 
-	err := wf.Next() // fires "init", callback returns "real", so advances to "real"
+	err := wf.Next(t.Context()) // fires "init", callback returns "real", so advances to "real"
 	require.NoError(t, err)
 	require.Equal(t, "real", wf.Current())
 }
@@ -174,17 +204,17 @@ func TestMultiSourceTransition(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(steps, sp, Config{}, nil, nil)
 
-	require.NoError(t, wf.Goto("one"))
+	require.NoError(t, wf.Goto(t.Context(), "one"))
 	require.Equal(t, "one", wf.Current())
 
-	require.NoError(t, wf.Goto("two"))
+	require.NoError(t, wf.Goto(t.Context(), "two"))
 	require.Equal(t, "two", wf.Current())
 
-	require.NoError(t, wf.Goto("three"))
+	require.NoError(t, wf.Goto(t.Context(), "three"))
 	require.Equal(t, "three", wf.Current())
 
 	// Loop back: three → two via the multi-source edge.
-	require.NoError(t, wf.Goto("two"))
+	require.NoError(t, wf.Goto(t.Context(), "two"))
 	require.Equal(t, "two", wf.Current())
 }
 
@@ -192,11 +222,11 @@ func TestCompletedStepsTracked(t *testing.T) {
 	sp := filepath.Join(t.TempDir(), "state.json")
 	wf := New(testSteps, sp, Config{}, nil, nil)
 
-	wf.Next() // → one
-	wf.Next() // → two
+	wf.Next(t.Context()) // → one
+	wf.Next(t.Context()) // → two
 	require.Equal(t, []string{"one"}, wf.State().CompletedSteps)
 
-	wf.Next() // → three (terminal)
+	wf.Next(t.Context()) // → three (terminal)
 	// The terminal step is marked completed by commitTerminal after the
 	// event fires, since no further transition will mark it later.
 	require.Equal(t, []string{"one", "two", "three"}, wf.State().CompletedSteps)
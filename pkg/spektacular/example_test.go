@@ -0,0 +1,42 @@
+package spektacular_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jumppad-labs/spektacular/pkg/spektacular"
+)
+
+// Drive the spec workflow headlessly: initialise a project, start a spec and
+// step through it, handing each instruction to whatever agent you embed.
+func Example() {
+	dir, err := os.MkdirTemp("", "spektacular-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p, err := spektacular.Init(ctx, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	first, err := p.Start(ctx, spektacular.Spec, "Billing Export")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(first.Step, strings.HasSuffix(first.Name, "-billing-export"))
+
+	next, err := p.Goto(ctx, spektacular.Spec, "requirements", nil)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(next.Step, next.Instruction != "")
+
+	// Output:
+	// overview true
+	// requirements true
+}
@@ -0,0 +1,280 @@
+// Package spektacular is the public Go API for embedding Spektacular in other
+// tools. It is a thin layer over the same internal packages the CLI uses, so a
+// workflow driven from Go produces exactly the state, files and step
+// instructions that `spektacular spec|plan|implement` would.
+//
+// Stability: Project, Workflow, StepResult, Config and the functions and
+// methods declared in this package are stable. Config is an alias of the
+// CLI's configuration type; its YAML shape follows the documented
+// config.yaml format and may gain fields in minor releases.
+package spektacular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jumppad-labs/spektacular/internal/config"
+	"github.com/jumppad-labs/spektacular/internal/launch"
+	"github.com/jumppad-labs/spektacular/internal/project"
+	"github.com/jumppad-labs/spektacular/internal/steps/implement"
+	"github.com/jumppad-labs/spektacular/internal/steps/plan"
+	"github.com/jumppad-labs/spektacular/internal/steps/spec"
+	"github.com/jumppad-labs/spektacular/internal/store"
+	"github.com/jumppad-labs/spektacular/internal/workflow"
+)
+
+// Config is the project configuration read from .spektacular/config.yaml.
+type Config = config.Config
+
+// DefaultConfig returns the configuration used when a project has no
+// config.yaml.
+func DefaultConfig() Config {
+	return config.NewDefault()
+}
+
+// LoadConfig reads and validates a config.yaml file, expanding ${VAR}
+// references from the environment.
+func LoadConfig(path string) (Config, error) {
+	return config.FromYAMLFile(path)
+}
+
+// Workflow names one of the three Spektacular workflows.
+type Workflow string
+
+const (
+	Spec      Workflow = "spec"
+	Plan      Workflow = "plan"
+	Implement Workflow = "implement"
+)
+
+// StepResult is the instruction produced by a workflow step: what the agent
+// driving the workflow should do next. Path is the workflow's primary
+// document (the spec file or plan.md).
+type StepResult struct {
+	Workflow    Workflow `json:"workflow"`
+	Step        string   `json:"step"`
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	Instruction string   `json:"instruction"`
+}
+
+// Project is a Spektacular project rooted at a directory.
+type Project struct {
+	root string
+	cfg  Config
+}
+
+// Init creates (or refreshes) the .spektacular directory structure under root
+// and returns the loaded project. Like `spektacular init`, it is safe to run
+// on an already initialised project: existing config is preserved.
+func Init(ctx context.Context, root string) (*Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := project.Init(root, true); err != nil {
+		return nil, fmt.Errorf("initialising project: %w", err)
+	}
+	return Load(ctx, root)
+}
+
+// Load opens the project rooted at root. A missing config.yaml yields the
+// default configuration; a config.yaml that exists but is invalid is an error.
+func Load(ctx context.Context, root string) (*Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving project root: %w", err)
+	}
+	cfg := config.NewDefault()
+	cfgPath := filepath.Join(abs, ".spektacular", "config.yaml")
+	if _, err := os.Stat(cfgPath); !errors.Is(err, fs.ErrNotExist) {
+		cfg, err = config.FromYAMLFile(cfgPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Project{root: abs, cfg: cfg}, nil
+}
+
+// Root returns the absolute project root.
+func (p *Project) Root() string { return p.root }
+
+// Config returns the project's configuration.
+func (p *Project) Config() Config { return p.cfg }
+
+// Steps returns the ordered step names of a workflow.
+func (p *Project) Steps(wf Workflow) ([]string, error) {
+	steps, err := stepsFor(wf)
+	if err != nil {
+		return nil, err
+	}
+	return workflow.New(steps, "", workflow.Config{}, nil, nil).StepNames(), nil
+}
+
+// OnExists policies for StartOptions.OnExists, matching `spec new --on-exists`.
+const (
+	OnExistsError     = spec.OnExistsError
+	OnExistsVersion   = spec.OnExistsVersion
+	OnExistsOverwrite = spec.OnExistsOverwrite
+)
+
+// ErrSpecExists is returned by StartWithOptions when an explicit spec ID
+// resolves to an existing spec and OnExists is OnExistsError.
+var ErrSpecExists = spec.ErrSpecExists
+
+// StartOptions adjusts how StartWithOptions creates a new spec. The fields
+// are ignored by the Plan and Implement workflows.
+type StartOptions struct {
+	// ID is an explicit spec id prefix, as `spec new --data '{"id":...}'`.
+	ID string
+	// OnExists selects what happens when ID resolves to an existing spec:
+	// OnExistsError (the default), OnExistsVersion to create a numbered
+	// sibling, or OnExistsOverwrite to archive the old spec and start over.
+	OnExists string
+	// Quick starts a quick spec, as `spec new --quick`: one question, then
+	// the agent writes every section.
+	Quick bool
+}
+
+// Start begins a new run of wf for name, replacing any workflow in progress,
+// and returns the first step's instruction. For Spec, name is normalised and
+// prefixed according to spec.id_method; the returned Name is the canonical
+// spec name to pass to Plan. For Plan, the spec must already exist; for
+// Implement, the plan must.
+func (p *Project) Start(ctx context.Context, wf Workflow, name string) (StepResult, error) {
+	return p.StartWithOptions(ctx, wf, name, StartOptions{})
+}
+
+// StartWithOptions is Start with the spec options in opts.
+func (p *Project) StartWithOptions(ctx context.Context, wf Workflow, name string, opts StartOptions) (StepResult, error) {
+	if err := ctx.Err(); err != nil {
+		return StepResult{}, err
+	}
+	steps, err := stepsFor(wf)
+	if err != nil {
+		return StepResult{}, err
+	}
+	st := store.NewFileStore(p.root, "project")
+	var started launch.SpecStart
+
+	switch wf {
+	case Spec:
+		started, err = launch.Spec(st, p.cfg, launch.SpecRequest{
+			Name:     name,
+			ID:       opts.ID,
+			OnExists: opts.OnExists,
+			Quick:    opts.Quick,
+		})
+		if err != nil {
+			return StepResult{}, err
+		}
+		steps = started.Steps
+	case Plan:
+		if err := launch.RequireSpec(st, p.cfg, name); err != nil {
+			return StepResult{}, err
+		}
+	case Implement:
+		if err := launch.RequirePlan(st, p.cfg, name); err != nil {
+			return StepResult{}, err
+		}
+	}
+
+	statePath := p.statePath()
+	_ = os.Remove(statePath)
+
+	capture := &resultCapture{}
+	w := workflow.New(steps, statePath, launch.WorkflowConfig(p.cfg, false), st, capture)
+	if wf == Spec {
+		started.Apply(w)
+	} else {
+		w.SetData("name", name)
+	}
+	if err := w.Next(ctx); err != nil {
+		return StepResult{}, err
+	}
+	return capture.result(wf, w.Current()), nil
+}
+
+// Goto advances the workflow in progress to step and returns its
+// instruction. data is merged into the workflow's persisted data first, as
+// the extra keys of `--data` are on the CLI.
+func (p *Project) Goto(ctx context.Context, wf Workflow, step string, data map[string]any) (StepResult, error) {
+	if err := ctx.Err(); err != nil {
+		return StepResult{}, err
+	}
+	steps, err := p.activeSteps(wf)
+	if err != nil {
+		return StepResult{}, err
+	}
+
+	capture := &resultCapture{}
+	w := workflow.New(steps, p.statePath(), launch.WorkflowConfig(p.cfg, false), store.NewFileStore(p.root, "project"), capture)
+	if _, ok := w.GetData("name"); !ok {
+		return StepResult{}, fmt.Errorf("no active %s workflow found — call Start first", wf)
+	}
+	for k, v := range data {
+		w.SetData(k, v)
+	}
+	if err := w.Goto(ctx, step); err != nil {
+		return StepResult{}, err
+	}
+	return capture.result(wf, w.Current()), nil
+}
+
+func (p *Project) statePath() string {
+	return filepath.Join(p.root, ".spektacular", "state.json")
+}
+
+// activeSteps returns the step configs for the workflow in progress; a quick
+// spec follows its shorter step list.
+func (p *Project) activeSteps(wf Workflow) ([]workflow.StepConfig, error) {
+	if wf == Spec {
+		return launch.SpecSteps(p.statePath()), nil
+	}
+	return stepsFor(wf)
+}
+
+func stepsFor(wf Workflow) ([]workflow.StepConfig, error) {
+	switch wf {
+	case Spec:
+		return spec.Steps(), nil
+	case Plan:
+		return plan.Steps(), nil
+	case Implement:
+		return implement.Steps(), nil
+	default:
+		return nil, fmt.Errorf("unknown workflow %q", wf)
+	}
+}
+
+// resultCapture is the workflow.ResultWriter used in place of the CLI's JSON
+// writer: it keeps the last step result so it can be returned to the caller.
+type resultCapture struct {
+	last any
+}
+
+func (c *resultCapture) WriteResult(v any) error {
+	c.last = v
+	return nil
+}
+
+// result converts the captured workflow-specific result into a StepResult.
+// Steps that produce no instruction yield a result naming only the step.
+func (c *resultCapture) result(wf Workflow, current string) StepResult {
+	switch r := c.last.(type) {
+	case spec.Result:
+		return StepResult{Workflow: wf, Step: r.Step, Name: r.SpecName, Path: r.SpecPath, Instruction: r.Instruction}
+	case plan.Result:
+		return StepResult{Workflow: wf, Step: r.Step, Name: r.PlanName, Path: r.PlanPath, Instruction: r.Instruction}
+	case implement.Result:
+		return StepResult{Workflow: wf, Step: r.Step, Name: r.PlanName, Path: r.PlanPath, Instruction: r.Instruction}
+	default:
+		return StepResult{Workflow: wf, Step: current}
+	}
+}
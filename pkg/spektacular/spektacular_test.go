@@ -0,0 +1,161 @@
+package spektacular
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_MissingConfigUsesDefaults(t *testing.T) {
+	p, err := Load(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, DefaultConfig(), p.Config())
+}
+
+func TestLoad_InvalidConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".spektacular"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".spektacular", "config.yaml"), []byte("spec:\n  provider: bogus\n"), 0644))
+
+	_, err := Load(context.Background(), dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "spec.provider")
+}
+
+func TestStart_CancelledContext(t *testing.T) {
+	p, err := Load(context.Background(), t.TempDir())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.Start(ctx, Spec, "feature")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStart_SpecCreatesFileAndState(t *testing.T) {
+	ctx := context.Background()
+	p, err := Init(ctx, t.TempDir())
+	require.NoError(t, err)
+
+	res, err := p.Start(ctx, Spec, "feature")
+	require.NoError(t, err)
+	require.Equal(t, Spec, res.Workflow)
+	require.Equal(t, "overview", res.Step)
+	require.FileExists(t, res.Path)
+	require.FileExists(t, filepath.Join(p.Root(), ".spektacular", "state.json"))
+}
+
+func TestStart_ImplementRequiresPlan(t *testing.T) {
+	ctx := context.Background()
+	p, err := Init(ctx, t.TempDir())
+	require.NoError(t, err)
+
+	_, err = p.Start(ctx, Implement, "missing")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "plan file not found")
+}
+
+func TestStart_PlanRequiresSpec(t *testing.T) {
+	ctx := context.Background()
+	p, err := Init(ctx, t.TempDir())
+	require.NoError(t, err)
+
+	spec, err := p.Start(ctx, Spec, "feature")
+	require.NoError(t, err)
+
+	_, err = p.Start(ctx, Plan, spec.Name+"x")
+	require.ErrorContains(t, err, "spec file not found")
+	require.ErrorContains(t, err, "did you mean "+spec.Name+"?")
+
+	res, err := p.Start(ctx, Plan, spec.Name)
+	require.NoError(t, err)
+	require.Equal(t, "overview", res.Step)
+}
+
+func TestGoto_WithoutStartErrors(t *testing.T) {
+	ctx := context.Background()
+	p, err := Init(ctx, t.TempDir())
+	require.NoError(t, err)
+
+	_, err = p.Goto(ctx, Plan, "discovery", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "call Start first")
+}
+
+func TestPlanWorkflowSteps(t *testing.T) {
+	p, err := Load(context.Background(), t.TempDir())
+	require.NoError(t, err)
+
+	steps, err := p.Steps(Plan)
+	require.NoError(t, err)
+	require.Equal(t, "new", steps[0])
+	require.Equal(t, "finished", steps[len(steps)-1])
+
+	_, err = p.Steps(Workflow("bogus"))
+	require.Error(t, err)
+}
+
+func TestGoto_SpecAppendQAFromConfig(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	_, err := Init(ctx, dir)
+	require.NoError(t, err)
+
+	cfg := DefaultConfig()
+	cfg.Spec.AppendQA = true
+	require.NoError(t, cfg.ToYAMLFile(filepath.Join(dir, ".spektacular", "config.yaml")))
+	p, err := Load(ctx, dir)
+	require.NoError(t, err)
+
+	_, err = p.Start(ctx, Spec, "feature")
+	require.NoError(t, err)
+
+	var res StepResult
+	for _, step := range []string{"requirements", "acceptance_criteria", "constraints", "technical_approach", "success_metrics", "non_goals", "verification"} {
+		res, err = p.Goto(ctx, Spec, step, nil)
+		require.NoError(t, err)
+	}
+	require.Equal(t, "verification", res.Step)
+	require.Contains(t, res.Instruction, "This project records clarifications in the spec")
+}
+
+func TestGoto_QuickSpecFollowsQuickSteps(t *testing.T) {
+	ctx := context.Background()
+	p, err := Init(ctx, t.TempDir())
+	require.NoError(t, err)
+
+	res, err := p.StartWithOptions(ctx, Spec, "feature", StartOptions{Quick: true})
+	require.NoError(t, err)
+	require.Equal(t, "quick", res.Step)
+
+	res, err = p.Goto(ctx, Spec, "finished", nil)
+	require.NoError(t, err)
+	require.Equal(t, "finished", res.Step)
+}
+
+func TestStartWithOptions_OnExists(t *testing.T) {
+	ctx := context.Background()
+	p, err := Init(ctx, t.TempDir())
+	require.NoError(t, err)
+
+	first, err := p.StartWithOptions(ctx, Spec, "billing", StartOptions{ID: "EXT-1"})
+	require.NoError(t, err)
+	require.Equal(t, "ext-1-billing", first.Name)
+
+	_, err = p.StartWithOptions(ctx, Spec, "billing", StartOptions{ID: "EXT-1"})
+	require.ErrorIs(t, err, ErrSpecExists)
+
+	versioned, err := p.StartWithOptions(ctx, Spec, "billing", StartOptions{ID: "EXT-1", OnExists: OnExistsVersion})
+	require.NoError(t, err)
+	require.Equal(t, "ext-1-billing-2", versioned.Name)
+
+	overwritten, err := p.StartWithOptions(ctx, Spec, "billing", StartOptions{ID: "EXT-1", OnExists: OnExistsOverwrite})
+	require.NoError(t, err)
+	require.Equal(t, "ext-1-billing", overwritten.Name)
+	archived, err := filepath.Glob(filepath.Join(filepath.Dir(first.Path), "archive", "ext-1-billing-*.md"))
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+}